@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"inkdown-cli/internal/auth"
+	"inkdown-cli/utils"
 
 	"github.com/spf13/cobra"
 )
@@ -9,15 +10,113 @@ import (
 var authCmd = &cobra.Command{
 	Use:   "auth",
 	Short: "Authentication commands",
-	Run: func(cmd *cobra.Command, args []string) {
-		auth.Auth()
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return printStatus(auth.Auth(cmd.Context()))
 	},
 }
 
+var authLoginCmd = &cobra.Command{
+	Use:   "login",
+	Short: "Log in to Inkdown and optionally GitHub with the active profile",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return printStatus(auth.Auth(cmd.Context()))
+	},
+}
+
+var authListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List auth profiles",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		profiles, err := auth.List()
+		if err != nil {
+			return err
+		}
+		if utils.JSONOutput() {
+			return utils.PrintJSON(profiles)
+		}
+		return nil
+	},
+}
+
+var authSwitchCmd = &cobra.Command{
+	Use:   "switch <profile>",
+	Short: "Make a profile the one used when --profile is not given",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return printStatus(auth.Switch(args[0]))
+	},
+}
+
+var authGitHubCmd = &cobra.Command{
+	Use:   "github",
+	Short: "Connect a GitHub account for publishing",
+	Long: `Run GitHub's device flow and store the token under the active profile,
+where plugin and theme publish pick it up.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return printStatus(auth.GitHubLogin(cmd.Context()))
+	},
+}
+
+var authStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the logged-in account, verify its tokens and where they are stored",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return printStatus(auth.Whoami())
+	},
+}
+
+var whoamiCmd = &cobra.Command{
+	Use:   "whoami",
+	Short: "Show the logged-in account and verify its tokens",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return printStatus(auth.Whoami())
+	},
+}
+
+var (
+	logoutAll  bool
+	logoutOpts auth.LogoutOptions
+)
+
 var logoutCmd = &cobra.Command{
 	Use:   "logout",
 	Short: "Logout from Inkdown CLI",
-	Run: func(cmd *cobra.Command, args []string) {
-		auth.Logout()
+	Long: `Revoke the profile's Inkdown token on the server and delete it and the
+profile's GitHub token from the credential store.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !logoutAll {
+			return printStatus(auth.Logout(logoutOpts))
+		}
+
+		statuses, err := auth.LogoutAll(logoutOpts)
+		if statuses != nil && utils.JSONOutput() {
+			if jsonErr := utils.PrintJSON(statuses); jsonErr != nil {
+				return jsonErr
+			}
+		}
+		return err
 	},
 }
+
+func init() {
+	logoutCmd.Flags().BoolVar(&logoutAll, "all", false, "Log out every stored profile")
+	logoutCmd.Flags().BoolVar(&logoutOpts.RevokeGitHub, "revoke-github", false, "Also revoke the GitHub OAuth authorization")
+
+	authCmd.AddCommand(authLoginCmd)
+	authCmd.AddCommand(authListCmd)
+	authCmd.AddCommand(authSwitchCmd)
+	authCmd.AddCommand(authGitHubCmd)
+	authCmd.AddCommand(authStatusCmd)
+}
+
+// printStatus emits an auth command's status object in JSON mode; in text
+// mode the command has already reported it. A status returned with an error
+// is still emitted.
+func printStatus(status *auth.Status, err error) error {
+	if status != nil && utils.JSONOutput() {
+		if jsonErr := utils.PrintJSON(status); jsonErr != nil {
+			return jsonErr
+		}
+	}
+	return err
+}
@@ -0,0 +1,94 @@
+package plugin
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"inkdown-cli/config"
+	"inkdown-cli/internal/build"
+	"inkdown-cli/utils"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	buildPath   string
+	buildDev    bool
+	buildScript bool
+	buildPM     string
+)
+
+var buildCmd = &cobra.Command{
+	Use:   "build",
+	Short: "Bundle the plugin into main.js",
+	Long: `Bundle src/main.ts into main.js with the built-in esbuild bundler, using the
+same settings as the template's esbuild.config.mjs. No Node.js or bun install
+is needed unless the plugin imports packages from node_modules.
+
+Use --script, or "build": {"script": true} in .inkrc.json, to run the
+"build" script of package.json instead. Publishing follows .inkrc.json.
+
+Dependencies are installed with the package manager named by --pm, by
+"build": {"packageManager": "..."} in .inkrc.json, by the packageManager field
+of package.json or by the lockfile, in that order; npm is the fallback.
+Installs are frozen to the lockfile when there is one.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if buildPath == "" {
+			buildPath = "."
+		}
+
+		abs, err := filepath.Abs(buildPath)
+		if err != nil {
+			return err
+		}
+
+		info, err := os.Stat(abs)
+		if err != nil {
+			return fmt.Errorf("path not found: %s", abs)
+		}
+		if !info.IsDir() {
+			return fmt.Errorf("path must be a directory: %s", abs)
+		}
+
+		project, err := config.LoadProject(abs)
+		if err != nil {
+			return err
+		}
+
+		builder := "esbuild"
+		if buildScript || project.Build.Script {
+			builder = "script"
+			err = build.Script(abs, buildPM)
+		} else {
+			if build.NeedsInstall(abs) {
+				err = build.Install(abs, buildPM)
+			}
+			if err == nil {
+				err = build.Bundle(abs, build.Options{Production: !buildDev})
+			}
+		}
+		if err != nil {
+			return err
+		}
+
+		if utils.JSONOutput() {
+			return utils.PrintJSON(map[string]interface{}{
+				"kind":       "plugin",
+				"path":       abs,
+				"outfile":    filepath.Join(abs, build.Outfile),
+				"builder":    builder,
+				"production": !buildDev,
+			})
+		}
+		return nil
+	},
+}
+
+func init() {
+	buildCmd.Flags().StringVarP(&buildPath, "path", "p", ".", "Path to the plugin")
+	buildCmd.Flags().BoolVar(&buildDev, "dev", false, "Skip minification and inline a source map")
+	buildCmd.Flags().BoolVar(&buildScript, "script", false, "Run the package.json build script instead of the built-in bundler")
+	buildCmd.Flags().StringVar(&buildPM, "pm", "", "Package manager for installing dependencies: npm, pnpm, yarn or bun (detected by default)")
+	PluginCmd.AddCommand(buildCmd)
+}
@@ -0,0 +1,136 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"inkdown-cli/internal/build"
+	"inkdown-cli/internal/vault"
+	"inkdown-cli/internal/watch"
+	"inkdown-cli/utils"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	devPath    string
+	devVault   string
+	devSymlink bool
+	devPM      string
+)
+
+// devFiles are installed into the vault after every build.
+var devFiles = []string{build.Outfile, "manifest.json", "styles.css"}
+
+var devCmd = &cobra.Command{
+	Use:   "dev",
+	Short: "Rebuild the plugin on change and install it into a local vault",
+	Long: `Build the plugin in watch mode. After every successful build, main.js,
+manifest.json and styles.css are copied (or symlinked with --symlink) into
+<vault>/.inkdown/plugins/<id>, and the .reload marker file there is rewritten
+so a running Inkdown reloads the plugin.
+
+Press Ctrl-C to stop.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if devPath == "" {
+			devPath = "."
+		}
+
+		abs, err := filepath.Abs(devPath)
+		if err != nil {
+			return err
+		}
+		if info, err := os.Stat(abs); err != nil || !info.IsDir() {
+			return fmt.Errorf("path must be a plugin directory: %s", abs)
+		}
+
+		vaultDir, err := vault.Open(devVault)
+		if err != nil {
+			return err
+		}
+
+		id, err := pluginID(abs)
+		if err != nil {
+			return err
+		}
+		dest := vault.PluginDir(vaultDir, id)
+
+		if build.NeedsInstall(abs) {
+			if err := build.Install(abs, devPM); err != nil {
+				return err
+			}
+		}
+
+		// esbuild and the poller run install concurrently.
+		var mu sync.Mutex
+		install := func() error {
+			mu.Lock()
+			defer mu.Unlock()
+
+			synced, err := vault.Sync(abs, dest, devFiles, devSymlink)
+			if err != nil {
+				return err
+			}
+			if err := vault.MarkReload(dest); err != nil {
+				return fmt.Errorf("could not write reload marker: %v", err)
+			}
+			utils.Info("Installed %s into %s", strings.Join(synced, ", "), dest)
+			return nil
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+
+		// esbuild only watches the bundle's sources.
+		go watch.Poll(ctx, abs, watch.DefaultInterval, func() []string {
+			return []string{"manifest.json", "styles.css"}
+		}, func(changed []string) {
+			utils.Info("Changed: %s", strings.Join(changed, ", "))
+			if err := install(); err != nil {
+				utils.Error("%v", err)
+			}
+		})
+
+		utils.Info("Watching %s (Ctrl-C to stop)", abs)
+		if err := build.Watch(ctx, abs, build.Options{}, install); err != nil {
+			return err
+		}
+
+		utils.Info("Stopped watching.")
+		return nil
+	},
+}
+
+// pluginID reads the id the app installs the plugin under.
+func pluginID(dir string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "manifest.json"))
+	if err != nil {
+		return "", fmt.Errorf("could not read manifest.json: %v", err)
+	}
+
+	var manifest struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return "", fmt.Errorf("invalid manifest.json: %v", err)
+	}
+	if manifest.ID == "" || strings.ContainsAny(manifest.ID, `/\`) || manifest.ID == "." || manifest.ID == ".." {
+		return "", fmt.Errorf("manifest.json needs a valid 'id' to install the plugin into a vault")
+	}
+	return manifest.ID, nil
+}
+
+func init() {
+	devCmd.Flags().StringVarP(&devPath, "path", "p", ".", "Path to the plugin")
+	devCmd.Flags().StringVar(&devVault, "vault", "", "Path to the Inkdown vault to install the plugin into")
+	devCmd.Flags().BoolVar(&devSymlink, "symlink", false, "Symlink the built files into the vault instead of copying them")
+	devCmd.Flags().StringVar(&devPM, "pm", "", "Package manager for installing dependencies: npm, pnpm, yarn or bun (detected by default)")
+	devCmd.MarkFlagRequired("vault")
+	PluginCmd.AddCommand(devCmd)
+}
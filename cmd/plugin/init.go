@@ -5,6 +5,7 @@ import (
 	"path/filepath"
 
 	"inkdown-cli/internal/generator"
+	"inkdown-cli/utils"
 
 	"github.com/spf13/cobra"
 )
@@ -24,20 +25,28 @@ var initCmd = &cobra.Command{
 		}
 
 		abs, err := filepath.Abs(initPath)
-
 		if err != nil {
-			fmt.Printf("Could not initialize the plugin: %s\n", err)
+			return fmt.Errorf("could not initialize the plugin: %v", err)
 		}
 
-		fmt.Printf("Initializing Inkdown plugin project in the path: %s\n", abs)
+		utils.Printf("Initializing Inkdown plugin project in the path: %s\n", abs)
 
 		if name != "" {
-			fmt.Printf("  Name: %s\n", name)
+			if id := generator.PluginID(name); len(id) < 2 {
+				return fmt.Errorf("cannot derive a plugin id from the name %q: it needs at least two ASCII letters or digits", name)
+			}
+			utils.Printf("  Name: %s\n", name)
 		}
 
 		templateDir := "plugin"
 
-		generator.CopyPluginTemplate(&templateDir, &abs, &name, &description)
+		if err := generator.CopyPluginTemplate(&templateDir, &abs, &name, &description); err != nil {
+			return err
+		}
+
+		if utils.JSONOutput() {
+			return utils.PrintJSON(map[string]string{"kind": "plugin", "path": abs, "name": name})
+		}
 		return nil
 	},
 }
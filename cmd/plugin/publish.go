@@ -1,16 +1,19 @@
 package plugin
 
 import (
-	"fmt"
 	"os"
 	"path/filepath"
 
 	"inkdown-cli/internal/publish"
+	"inkdown-cli/utils"
 
 	"github.com/spf13/cobra"
 )
 
-var pluginPath string
+var (
+	pluginPath  string
+	publishOpts publish.Options
+)
 
 var publishCmd = &cobra.Command{
 	Use:   "publish",
@@ -25,24 +28,27 @@ var publishCmd = &cobra.Command{
 		}
 
 		if name != "" {
-			fmt.Printf("  Name: %s\n", name)
+			utils.Printf("  Name: %s\n", name)
 		}
 
-		_, err := filepath.Abs(pluginPath)
+		if _, err := filepath.Abs(pluginPath); err != nil {
+			return err
+		}
 
+		result, err := publish.PublishPlugin(&pluginPath, publishOpts)
 		if err != nil {
-			fmt.Printf("This path does not exist: %s\n", pluginPath)
 			return err
 		}
 
-		link, err := publish.PublishPlugin(&pluginPath)
+		if utils.JSONOutput() {
+			return utils.PrintJSON(result)
+		}
 
-		if err != nil {
-			fmt.Printf("Failed to publish plugin: %v\n", err)
-			return err
+		if publishOpts.DryRun || result.Aborted {
+			return nil
 		}
 
-		fmt.Printf("Plugin PR published successfully, you can check in this link: %s\n", link)
+		utils.Printf("Plugin PR published successfully, you can check in this link: %s\n", result.PRURL)
 
 		return nil
 	},
@@ -51,6 +57,13 @@ var publishCmd = &cobra.Command{
 func init() {
 
 	publishCmd.Flags().StringVarP(&pluginPath, "path", "d", ".", "Path to the plugin")
+	publishCmd.Flags().BoolVar(&publishOpts.DryRun, "dry-run", false, "Print what would be published without changing anything on GitHub")
+	publishCmd.Flags().BoolVar(&publishOpts.Resume, "resume", false, "Continue an interrupted publish of the same version, skipping completed steps")
+	publishCmd.Flags().BoolVarP(&publishOpts.Yes, "yes", "y", false, "Never prompt; use --pr-title or a generated PR title (does not imply --overwrite)")
+	publishCmd.Flags().BoolVar(&publishOpts.Overwrite, "overwrite", false, "Replace an existing release with the same tag without asking")
+	publishCmd.Flags().StringVar(&publishOpts.PRTitle, "pr-title", "", "Title of the registry pull request")
+	publishCmd.Flags().StringVar(&publishOpts.PRBodyFile, "pr-body-file", "", "File whose contents replace the generated pull request body")
+	publishCmd.Flags().StringVar(&publishOpts.PackageManager, "pm", "", "Package manager for installing dependencies: npm, pnpm, yarn or bun (detected by default)")
 
 	PluginCmd.AddCommand(publishCmd)
 }
@@ -0,0 +1,26 @@
+package plugin
+
+import (
+	"os"
+
+	"inkdown-cli/internal/validate"
+
+	"github.com/spf13/cobra"
+)
+
+var schemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print the JSON Schema of plugin manifest.json files",
+	Long: `Print the JSON Schema of plugin manifest.json files, for editor integration.
+
+Reference it from manifest.json with a "$schema" field, or save the output and
+point your editor's JSON schema settings at it.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		_, err := os.Stdout.Write(validate.PluginManifestSchema)
+		return err
+	},
+}
+
+func init() {
+	PluginCmd.AddCommand(schemaCmd)
+}
@@ -1,25 +1,81 @@
 package cmd
 
 import (
+	"fmt"
+	"os"
+
 	"inkdown-cli/cmd/plugin"
 	"inkdown-cli/cmd/theme"
+	"inkdown-cli/config"
+	"inkdown-cli/utils"
 
 	"github.com/spf13/cobra"
 )
 
+var (
+	outputFormat string
+	profile      string
+	apiURL       string
+)
+
 var rootCmd = &cobra.Command{
 	Use:   "ink",
 	Short: "Inkdown cli for publishing plugins and themes easily",
+	// Execute reports errors itself, once and without the usage block.
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		switch outputFormat {
+		case "text":
+		case "json":
+			utils.SetJSONOutput(true)
+		default:
+			return fmt.Errorf("invalid --output %q: must be text or json", outputFormat)
+		}
+
+		if profile != "" {
+			if err := config.ValidateProfileName(profile); err != nil {
+				return err
+			}
+			config.SelectProfile(profile)
+		}
+
+		if apiURL != "" {
+			if _, err := config.ParseAPIURL(apiURL); err != nil {
+				return err
+			}
+			config.SelectAPIURL(apiURL)
+		}
+		return nil
+	},
 }
 
+// Execute runs the CLI and reports a failure once: as a JSON error object
+// when -o json has not printed a document yet, otherwise on stderr. It exits
+// with status 1 on failure.
 func Execute() {
-	cobra.CheckErr(rootCmd.Execute())
+	err := rootCmd.Execute()
+	if err == nil {
+		return
+	}
+
+	if utils.JSONOutput() && !utils.JSONEmitted() {
+		_ = utils.PrintJSON(map[string]string{"error": err.Error()})
+	} else {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+	}
+	os.Exit(1)
 }
 
 func init() {
+	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", "text", "Output format: text or json")
+	rootCmd.PersistentFlags().StringVar(&profile, "profile", "", "Auth profile to use instead of the current one")
+	rootCmd.PersistentFlags().StringVar(&apiURL, "api-url", "", "Inkdown API: prod, staging, local or a URL (overrides INK_API_URL and the profile)")
+
 	rootCmd.AddCommand(plugin.PluginCmd)
 	rootCmd.AddCommand(theme.ThemeCmd)
 
 	rootCmd.AddCommand(authCmd)
 	rootCmd.AddCommand(logoutCmd)
+	rootCmd.AddCommand(whoamiCmd)
 }
@@ -0,0 +1,148 @@
+package theme
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"inkdown-cli/internal/preview"
+	"inkdown-cli/internal/validate"
+	"inkdown-cli/internal/vault"
+	"inkdown-cli/internal/watch"
+	"inkdown-cli/utils"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	devPath  string
+	devVault string
+	devPort  int
+)
+
+var devCmd = &cobra.Command{
+	Use:   "dev",
+	Short: "Preview the theme live and sync it into a local vault",
+	Long: `Watch theme.json and the mode stylesheets. On every change the theme is
+validated again and, when it has no errors, synced into
+<vault>/.inkdown/themes/<directory name> with the .reload marker file
+rewritten so a running Inkdown reloads it.
+
+A preview page of representative Markdown elements is served on localhost,
+with a toggle between the theme's modes. It reloads the changed stylesheets
+by itself.
+
+Press Ctrl-C to stop.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if devPath == "" {
+			devPath = "."
+		}
+
+		abs, err := filepath.Abs(devPath)
+		if err != nil {
+			return err
+		}
+		if _, err := validate.LoadThemeManifest(abs); err != nil {
+			return fmt.Errorf("%v (make sure you are in the theme root)", err)
+		}
+
+		var dest string
+		if devVault != "" {
+			vaultDir, err := vault.Open(devVault)
+			if err != nil {
+				return err
+			}
+			dest = vault.ThemeDir(vaultDir, themeID(abs))
+		}
+
+		files := func() []string {
+			files := []string{"theme.json"}
+			if manifest, err := validate.LoadThemeManifest(abs); err == nil {
+				for _, mode := range manifest.CSSModes() {
+					files = append(files, mode+".css")
+				}
+			}
+			return files
+		}
+
+		// sync validates the theme and installs it when it has no errors.
+		sync := func() bool {
+			if _, err := validate.ValidateTheme(abs, validate.ThemeOptions{}); err != nil {
+				utils.Warn("Not syncing until the errors above are fixed.")
+				return false
+			}
+			if dest == "" {
+				return true
+			}
+
+			synced, err := vault.Sync(abs, dest, files(), false)
+			if err == nil {
+				err = vault.MarkReload(dest)
+			}
+			if err != nil {
+				utils.Error("Could not sync the theme: %v", err)
+				return false
+			}
+			utils.Info("Installed %s into %s", strings.Join(synced, ", "), dest)
+			return true
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+
+		sync()
+
+		listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", devPort))
+		if err != nil {
+			return fmt.Errorf("could not start the preview server: %v", err)
+		}
+		previewServer := preview.New(abs)
+		server := &http.Server{Handler: previewServer.Handler()}
+		go func() {
+			if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				utils.Error("Preview server stopped: %v", err)
+			}
+		}()
+
+		utils.Success("Preview at http://%s", listener.Addr())
+		utils.Info("Watching %s (Ctrl-C to stop)", abs)
+
+		watch.Poll(ctx, abs, watch.DefaultInterval, files, func(changed []string) {
+			utils.Info("Changed: %s", strings.Join(changed, ", "))
+			valid := sync()
+			previewServer.Notify(preview.Change{Files: changed, Valid: valid})
+		})
+
+		// Open event streams never finish on their own, so close instead of
+		// waiting in Shutdown.
+		server.Close()
+		utils.Info("Stopped watching.")
+		return nil
+	},
+}
+
+var nonIDChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// themeID names the theme's directory in the vault after its project
+// directory, which is also its repository name once published.
+func themeID(dir string) string {
+	id := strings.Trim(nonIDChars.ReplaceAllString(strings.ToLower(filepath.Base(dir)), "-"), "-")
+	if id == "" {
+		return "theme"
+	}
+	return id
+}
+
+func init() {
+	devCmd.Flags().StringVarP(&devPath, "path", "p", ".", "Path to the theme")
+	devCmd.Flags().StringVar(&devVault, "vault", "", "Path to an Inkdown vault to sync the theme into")
+	devCmd.Flags().IntVar(&devPort, "port", 4321, "Port of the preview server on localhost (0 picks a free port)")
+	ThemeCmd.AddCommand(devCmd)
+}
@@ -3,6 +3,11 @@ package theme
 import (
 	"fmt"
 	"path/filepath"
+	"slices"
+
+	"inkdown-cli/internal/generator"
+	"inkdown-cli/internal/validate"
+	"inkdown-cli/utils"
 
 	"github.com/spf13/cobra"
 )
@@ -10,36 +15,58 @@ import (
 var (
 	initPath string
 	name     string
+	author   string
+	modes    []string
 )
 
 var initCmd = &cobra.Command{
 	Use:   "init",
 	Short: "Initialize a new Inkdown theme project",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 
 		if initPath == "" {
 			initPath = "."
 		}
 
-		fmt.Printf("  Path: %s\n", initPath)
+		for i, mode := range modes {
+			if !validate.ModeName.MatchString(mode) {
+				return fmt.Errorf("invalid mode %q: use lowercase letters, digits and dashes", mode)
+			}
+			if slices.Contains(modes[:i], mode) {
+				return fmt.Errorf("duplicate mode %q", mode)
+			}
+		}
+
+		utils.Printf("  Path: %s\n", initPath)
 		if name != "" {
-			fmt.Printf("  Name: %s\n", name)
+			utils.Printf("  Name: %s\n", name)
 		}
 
 		abs, err := filepath.Abs(initPath)
-
 		if err != nil {
-			fmt.Printf("This path does not exist: %s", initPath)
+			return err
 		}
 
-		fmt.Printf("Initializing Inkdown theme project in path: %s\n", abs)
+		utils.Printf("Initializing Inkdown theme project in path: %s\n", abs)
 
+		templateDir := "theme"
+
+		if err := generator.CopyThemeTemplate(&templateDir, &abs, &name, &author, modes); err != nil {
+			return err
+		}
+
+		if utils.JSONOutput() {
+			return utils.PrintJSON(map[string]interface{}{"kind": "theme", "path": abs, "name": name, "modes": modes})
+		}
+		return nil
 	},
 }
 
 func init() {
 	initCmd.Flags().StringVarP(&initPath, "path", "p", ".", "Path to initialize the theme")
 	initCmd.Flags().StringVarP(&name, "name", "n", "", "Theme name")
+	initCmd.Flags().StringVarP(&author, "author", "a", "", "Theme author")
+	initCmd.Flags().StringSliceVarP(&modes, "modes", "m", []string{"dark", "light"}, "Comma-separated color modes to generate a stylesheet for")
 
 	ThemeCmd.AddCommand(initCmd)
 }
@@ -1 +1,69 @@
 package theme
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"inkdown-cli/internal/publish"
+	"inkdown-cli/utils"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	themePath   string
+	publishOpts publish.Options
+)
+
+var publishCmd = &cobra.Command{
+	Use:   "publish",
+	Short: "Publish a theme",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if themePath == "" {
+			themePath = "."
+		}
+
+		abs, err := filepath.Abs(themePath)
+		if err != nil {
+			return err
+		}
+
+		info, err := os.Stat(abs)
+		if err != nil {
+			return fmt.Errorf("path not found: %s", abs)
+		}
+		if !info.IsDir() {
+			return fmt.Errorf("path must be a directory: %s", abs)
+		}
+
+		result, err := publish.PublishTheme(&abs, publishOpts)
+		if err != nil {
+			return err
+		}
+
+		if utils.JSONOutput() {
+			return utils.PrintJSON(result)
+		}
+
+		if publishOpts.DryRun || result.Aborted {
+			return nil
+		}
+
+		utils.Printf("Theme PR published successfully, you can check in this link: %s\n", result.PRURL)
+
+		return nil
+	},
+}
+
+func init() {
+	publishCmd.Flags().StringVarP(&themePath, "path", "p", ".", "Path to the theme")
+	publishCmd.Flags().BoolVar(&publishOpts.DryRun, "dry-run", false, "Print what would be published without changing anything on GitHub")
+	publishCmd.Flags().BoolVar(&publishOpts.Resume, "resume", false, "Continue an interrupted publish of the same version, skipping completed steps")
+	publishCmd.Flags().BoolVarP(&publishOpts.Yes, "yes", "y", false, "Never prompt; use --pr-title or a generated PR title (does not imply --overwrite)")
+	publishCmd.Flags().BoolVar(&publishOpts.Overwrite, "overwrite", false, "Replace an existing release with the same tag without asking")
+	publishCmd.Flags().StringVar(&publishOpts.PRTitle, "pr-title", "", "Title of the registry pull request")
+	publishCmd.Flags().StringVar(&publishOpts.PRBodyFile, "pr-body-file", "", "File whose contents replace the generated pull request body")
+
+	ThemeCmd.AddCommand(publishCmd)
+}
@@ -1,47 +1,29 @@
 package theme
 
 import (
-	"fmt"
-	"os"
-	"path/filepath"
-
+	"inkdown-cli/internal/reporter"
 	"inkdown-cli/internal/validate"
 
 	"github.com/spf13/cobra"
 )
 
-var validatePath string
+var (
+	validateRun  reporter.ValidateCommand
+	validateOpts validate.ThemeOptions
+)
 
 var validateCmd = &cobra.Command{
 	Use:   "validate",
 	Short: "Validate a theme project",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		if validatePath == "" {
-			validatePath = "."
-		}
-
-		abs, err := filepath.Abs(validatePath)
-		if err != nil {
-			return err
-		}
-
-		info, err := os.Stat(abs)
-		if err != nil {
-			return fmt.Errorf("path not found: %s", abs)
-		}
-		if !info.IsDir() {
-			return fmt.Errorf("path must be a directory: %s", abs)
-		}
-
-		if err := validate.ValidateTheme(abs); err != nil {
-			return err
-		}
-
-		return nil
+		return validateRun.Run(func(dir string) (*validate.Report, error) {
+			return validate.ValidateTheme(dir, validateOpts)
+		})
 	},
 }
 
 func init() {
-	validateCmd.Flags().StringVarP(&validatePath, "path", "p", ".", "Path to the theme")
+	validateRun.AddFlags(validateCmd, "theme")
+	validateCmd.Flags().BoolVar(&validateOpts.A11y, "a11y", false, "Check WCAG 2.1 color contrast of the theme's text and UI colors")
 	ThemeCmd.AddCommand(validateCmd)
 }
@@ -0,0 +1,110 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// Environments maps the names accepted wherever an API URL is expected to
+// the Inkdown API endpoints.
+var Environments = map[string]string{
+	"prod":    "https://api.inkdown.app/api/v1",
+	"staging": "https://api.staging.inkdown.app/api/v1",
+	"local":   "http://localhost:8080/api/v1",
+}
+
+// DefaultEnvironment is used when nothing else selects an endpoint.
+const DefaultEnvironment = "prod"
+
+// APIEndpoint is the resolved Inkdown API base URL.
+type APIEndpoint struct {
+	URL string `json:"url"`
+	// Environment is the name of a built-in endpoint, if URL is one.
+	Environment string `json:"environment,omitempty"`
+	// Source is where the endpoint was configured: "flag", "env" (INK_API_URL),
+	// "config" (the profile) or "default".
+	Source string `json:"source"`
+}
+
+func (e APIEndpoint) String() string {
+	if e.Environment != "" {
+		return fmt.Sprintf("%s (%s, from %s)", e.URL, e.Environment, e.Source)
+	}
+	return fmt.Sprintf("%s (from %s)", e.URL, e.Source)
+}
+
+// apiURLOverride is the value of the global --api-url flag.
+var apiURLOverride string
+
+// SelectAPIURL makes value, an environment name or URL, the API endpoint
+// for this run. It is validated by ParseAPIURL first.
+func SelectAPIURL(value string) {
+	apiURLOverride = value
+}
+
+// APIURLOverride returns the --api-url value, if any.
+func APIURLOverride() string {
+	return apiURLOverride
+}
+
+// ParseAPIURL resolves an environment name or validates a URL. Plain HTTP is
+// only accepted for localhost, so tokens never cross the network unencrypted.
+func ParseAPIURL(value string) (APIEndpoint, error) {
+	if u, ok := Environments[value]; ok {
+		return APIEndpoint{URL: u, Environment: value}, nil
+	}
+
+	u, err := url.Parse(value)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return APIEndpoint{}, fmt.Errorf("invalid API URL %q: use prod, staging, local or an http(s) URL", value)
+	}
+	if u.Scheme == "http" && !isLoopback(u.Hostname()) {
+		return APIEndpoint{}, fmt.Errorf("invalid API URL %q: HTTPS is required except for localhost", value)
+	}
+
+	endpoint := APIEndpoint{URL: strings.TrimRight(value, "/")}
+	for name, envURL := range Environments {
+		if envURL == endpoint.URL {
+			endpoint.Environment = name
+		}
+	}
+	return endpoint, nil
+}
+
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// APIEndpoint resolves the API URL for profile: --api-url, then INK_API_URL,
+// then the profile's api_url, then the production endpoint.
+func (c *Config) APIEndpoint(profile string) (APIEndpoint, error) {
+	type layer struct{ value, source string }
+	layers := []layer{
+		{apiURLOverride, "flag"},
+		{os.Getenv("INK_API_URL"), "env"},
+	}
+	if c.HasProfile(profile) {
+		layers = append(layers, layer{c.Profile(profile).APIURL, "config"})
+	}
+
+	for _, l := range layers {
+		if l.value == "" {
+			continue
+		}
+		endpoint, err := ParseAPIURL(l.value)
+		if err != nil {
+			return APIEndpoint{}, fmt.Errorf("%v (from %s)", err, l.source)
+		}
+		endpoint.Source = l.source
+		return endpoint, nil
+	}
+
+	return APIEndpoint{URL: Environments[DefaultEnvironment], Environment: DefaultEnvironment, Source: "default"}, nil
+}
@@ -5,13 +5,106 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
 
 	"github.com/adrg/xdg"
 )
 
+// DefaultProfile is used until another profile is selected.
+const DefaultProfile = "default"
+
+// Profile is one account the CLI can act as. Its tokens live in the
+// credential store under the profile's name.
+type Profile struct {
+	Email string `json:"email,omitempty"`
+	// APIURL is an environment name or URL, see ParseAPIURL.
+	APIURL string `json:"api_url,omitempty"`
+}
+
 type Config struct {
+	// Token is the plaintext API token written by earlier versions. It is
+	// only read to migrate it to the credential store.
 	Token string `json:"token,omitempty"`
+	// Email is the account of earlier, single-profile versions; Load moves
+	// it to the default profile.
 	Email string `json:"email,omitempty"`
+
+	// CurrentProfile is the profile chosen with `ink auth switch`.
+	CurrentProfile string              `json:"current_profile,omitempty"`
+	Profiles       map[string]*Profile `json:"profiles,omitempty"`
+}
+
+// profileOverride is the profile selected by the global --profile flag.
+var profileOverride string
+
+// SelectProfile makes name the active profile for this run.
+func SelectProfile(name string) {
+	profileOverride = name
+}
+
+var profileName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// ValidateProfileName rejects names that cannot be used as credential keys.
+func ValidateProfileName(name string) error {
+	if !profileName.MatchString(name) {
+		return fmt.Errorf("invalid profile name %q: use letters, digits, '.', '_' and '-'", name)
+	}
+	return nil
+}
+
+// ActiveProfile returns the profile commands act on: the --profile flag,
+// then the current profile, then "default".
+func (c *Config) ActiveProfile() string {
+	switch {
+	case profileOverride != "":
+		return profileOverride
+	case c.CurrentProfile != "":
+		return c.CurrentProfile
+	}
+	return DefaultProfile
+}
+
+// Profile returns the named profile, adding an empty one if it does not
+// exist yet.
+func (c *Config) Profile(name string) *Profile {
+	if c.Profiles == nil {
+		c.Profiles = map[string]*Profile{}
+	}
+	p, ok := c.Profiles[name]
+	if !ok {
+		p = &Profile{}
+		c.Profiles[name] = p
+	}
+	return p
+}
+
+// HasProfile reports whether name has been saved.
+func (c *Config) HasProfile(name string) bool {
+	_, ok := c.Profiles[name]
+	return ok
+}
+
+// RemoveProfile forgets name. When it was current, the default profile
+// becomes current if it exists.
+func (c *Config) RemoveProfile(name string) {
+	delete(c.Profiles, name)
+	if c.CurrentProfile == name {
+		c.CurrentProfile = ""
+		if c.HasProfile(DefaultProfile) {
+			c.CurrentProfile = DefaultProfile
+		}
+	}
+}
+
+// ProfileNames returns the saved profiles in alphabetical order.
+func (c *Config) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
 }
 
 func ConfigPath() string {
@@ -21,11 +114,9 @@ func ConfigPath() string {
 func Load() (*Config, error) {
 	configPath := ConfigPath()
 
-	fmt.Printf("DEBUG: Config directory: %s \n", configPath)
-
 	configDir := filepath.Dir(configPath)
 
-	if err := os.MkdirAll(configDir, 0755); err != nil {
+	if err := os.MkdirAll(configDir, 0700); err != nil {
 		return nil, err
 	}
 
@@ -37,6 +128,13 @@ func Load() (*Config, error) {
 		return cfg, nil
 	}
 
+	// Earlier versions wrote the directory and file world-readable.
+	for path, mode := range map[string]os.FileMode{configDir: 0700, configPath: 0600} {
+		if info, err := os.Stat(path); err == nil && info.Mode().Perm()&0077 != 0 {
+			_ = os.Chmod(path, mode)
+		}
+	}
+
 	data, err := os.ReadFile(configPath)
 	if err != nil {
 		return nil, err
@@ -51,6 +149,16 @@ func Load() (*Config, error) {
 		return nil, err
 	}
 
+	if cfg.Email != "" {
+		if p := cfg.Profile(DefaultProfile); p.Email == "" {
+			p.Email = cfg.Email
+		}
+		if cfg.CurrentProfile == "" {
+			cfg.CurrentProfile = DefaultProfile
+		}
+		cfg.Email = ""
+	}
+
 	return &cfg, nil
 }
 
@@ -58,7 +166,7 @@ func (c *Config) Save() error {
 	configPath := ConfigPath()
 	configDir := filepath.Dir(configPath)
 
-	if err := os.MkdirAll(configDir, 0755); err != nil {
+	if err := os.MkdirAll(configDir, 0700); err != nil {
 		return err
 	}
 
@@ -67,24 +175,5 @@ func (c *Config) Save() error {
 		return err
 	}
 
-	return os.WriteFile(configPath, data, 0644)
-}
-
-func (c *Config) IsAuthenticated() bool {
-	return c.Token != "" && c.Email != ""
-}
-
-func (c *Config) SetToken(token string) error {
-	c.Token = token
-	return c.Save()
-}
-
-func (c *Config) ClearToken() error {
-	c.Token = ""
-	return c.Save()
-}
-
-func (c *Config) SetEmail(email string) error {
-	c.Email = email
-	return c.Save()
+	return os.WriteFile(configPath, data, 0600)
 }
@@ -4,11 +4,28 @@ import "os"
 
 type Env struct {
 	ClientID string
+	// ClientSecret is the OAuth app secret, only needed to revoke a grant.
+	ClientSecret string
+
+	// GitHubToken is a token supplied by the environment (INK_GITHUB_TOKEN,
+	// then GITHUB_TOKEN), used instead of the token stored by `ink auth github`.
+	GitHubToken string
+
+	// GitHub endpoints. Empty values fall back to github.com; set them to
+	// target GitHub Enterprise Server or a local stand-in.
+	GitHubAPIURL    string
+	GitHubUploadURL string
+	GitHubWebURL    string
 }
 
 func LoadEnv() *Env {
 	return &Env{
-		ClientID: getEnv("CLIENT_ID", "Ov23liM0BAkzFlF1II7n"),
+		ClientID:        getEnv("CLIENT_ID", "Ov23liM0BAkzFlF1II7n"),
+		ClientSecret:    getEnv("INK_GITHUB_CLIENT_SECRET", ""),
+		GitHubToken:     getEnv("INK_GITHUB_TOKEN", getEnv("GITHUB_TOKEN", "")),
+		GitHubAPIURL:    getEnv("INK_GITHUB_API_URL", ""),
+		GitHubUploadURL: getEnv("INK_GITHUB_UPLOAD_URL", ""),
+		GitHubWebURL:    getEnv("INK_GITHUB_WEB_URL", ""),
 	}
 }
 
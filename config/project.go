@@ -0,0 +1,76 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ProjectFile is the per-project configuration file, read from the root of a
+// plugin or theme.
+const ProjectFile = ".inkrc.json"
+
+type Project struct {
+	Validate ValidateConfig `json:"validate"`
+	Build    BuildConfig    `json:"build"`
+}
+
+type ValidateConfig struct {
+	// Rules configures validation rules by id.
+	Rules map[string]RuleConfig `json:"rules,omitempty"`
+}
+
+// RuleConfig overrides a validation rule. In JSON it is either a severity
+// ("error", "warn" or "off") or an object with a severity, options or both.
+type RuleConfig struct {
+	Severity string `json:"severity,omitempty"`
+	// Options replace the rule's default option values by name.
+	Options map[string][]string `json:"options,omitempty"`
+}
+
+func (r *RuleConfig) UnmarshalJSON(data []byte) error {
+	var severity string
+	if err := json.Unmarshal(data, &severity); err == nil {
+		*r = RuleConfig{Severity: severity}
+		return nil
+	}
+
+	type plain RuleConfig
+	var p plain
+	if err := json.Unmarshal(data, &p); err != nil {
+		return fmt.Errorf("a rule must be a severity or an object with severity and options: %v", err)
+	}
+	*r = RuleConfig(p)
+	return nil
+}
+
+type BuildConfig struct {
+	// Script builds plugins with the "build" script of package.json instead
+	// of the built-in esbuild bundler.
+	Script bool `json:"script,omitempty"`
+	// PackageManager runs the script: "npm", "pnpm", "yarn" or "bun".
+	// Detected from package.json and lockfiles when empty.
+	PackageManager string `json:"packageManager,omitempty"`
+}
+
+// LoadProject reads the project file in dir. A missing file yields an empty
+// configuration.
+func LoadProject(dir string) (*Project, error) {
+	path := filepath.Join(dir, ProjectFile)
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &Project{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var project Project
+	if err := json.Unmarshal(data, &project); err != nil {
+		return nil, fmt.Errorf("invalid %s: %v", ProjectFile, err)
+	}
+
+	return &project, nil
+}
@@ -3,7 +3,9 @@ package auth
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
@@ -12,10 +14,8 @@ import (
 	"strings"
 
 	"inkdown-cli/config"
-)
-
-const (
-	apiBaseURL = "http://localhost:8080/api/v1"
+	"inkdown-cli/internal/credentials"
+	"inkdown-cli/utils"
 )
 
 type CLILoginRequest struct {
@@ -24,39 +24,167 @@ type CLILoginRequest struct {
 	Name     string `json:"name"`
 }
 
+// CLIToken describes a CLI token. Token is only set in the login response;
+// Name is the device name given at login.
+type CLIToken struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Token       string   `json:"token"`
+	TokenPrefix string   `json:"token_prefix"`
+	Scopes      []string `json:"scopes"`
+	CreatedAt   string   `json:"created_at"`
+	Message     string   `json:"message"`
+}
+
 type CLILoginResponse struct {
-	Success bool `json:"success"`
-	Data    struct {
-		ID          string   `json:"id"`
-		Name        string   `json:"name"`
-		Token       string   `json:"token"`
-		TokenPrefix string   `json:"token_prefix"`
-		Scopes      []string `json:"scopes"`
-		CreatedAt   string   `json:"created_at"`
-		Message     string   `json:"message"`
-	} `json:"data"`
-	Error string `json:"error,omitempty"`
-}
-
-func Auth() error {
+	Success bool     `json:"success"`
+	Data    CLIToken `json:"data"`
+	Error   string   `json:"error,omitempty"`
+}
+
+// Status describes the authentication state after an auth command.
+type Status struct {
+	// Action is what the command did: "login", "github-login", "logout",
+	// "switch" or "none".
+	Action        string `json:"action"`
+	Profile       string `json:"profile"`
+	Authenticated bool   `json:"authenticated"`
+	Email         string `json:"email,omitempty"`
+	ConfigPath    string `json:"config_path"`
+	// API is the endpoint the profile talks to.
+	API config.APIEndpoint `json:"api"`
+	// Credentials lists where each credential is stored; only status fills it.
+	Credentials []credentials.Entry `json:"credentials,omitempty"`
+	// Token and GitHub are the server-side checks of the stored tokens; only
+	// status fills them, for the tokens that exist.
+	Token  *TokenCheck  `json:"token,omitempty"`
+	GitHub *GitHubCheck `json:"github,omitempty"`
+
+	// Revoked reports that logout revoked the Inkdown token on the server.
+	Revoked bool `json:"revoked,omitempty"`
+	// GitHubDeleted and GitHubRevoked report that logout deleted the stored
+	// GitHub token and revoked its OAuth grant.
+	GitHubDeleted bool `json:"github_deleted,omitempty"`
+	GitHubRevoked bool `json:"github_revoked,omitempty"`
+}
+
+// session is the saved login of the active profile: the account in
+// config.json and its token in the credential store.
+type session struct {
+	cfg     *config.Config
+	store   *credentials.Store
+	profile string
+	api     config.APIEndpoint
+	email   string
+	token   string
+}
+
+// loadSession opens the session of the active profile.
+func loadSession() (*session, error) {
+	cfg, store, err := open()
+	if err != nil {
+		return nil, err
+	}
+	return newSession(cfg, store, cfg.ActiveProfile())
+}
+
+func open() (*config.Config, *credentials.Store, error) {
 	cfg, err := config.Load()
 	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
+		return nil, nil, fmt.Errorf("failed to load config: %w", err)
 	}
 
-	if cfg.IsAuthenticated() {
-		fmt.Printf("✓ You are already authenticated as: %s\n", cfg.Email)
-		fmt.Println("  Use 'ink logout' to sign out first.")
-		return nil
+	store, err := credentials.Open()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open credential store: %w", err)
 	}
+	return cfg, store, nil
+}
 
-	fmt.Println("Inkdown CLI Authentication")
-	fmt.Println("─────────────────────────────")
+func newSession(cfg *config.Config, store *credentials.Store, profile string) (*session, error) {
+	s := &session{cfg: cfg, store: store, profile: profile}
+	if cfg.HasProfile(profile) {
+		s.email = cfg.Profile(profile).Email
+	}
+
+	var err error
+	s.api, err = cfg.APIEndpoint(profile)
+	if err != nil {
+		return nil, err
+	}
+
+	s.token, _, err = store.Get(credentials.Key(profile, credentials.InkdownToken))
+	if err != nil && !errors.Is(err, credentials.ErrNotFound) {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *session) authenticated() bool {
+	return s.token != "" && s.email != ""
+}
+
+func (s *session) status(action string) *Status {
+	return &Status{
+		Action:        action,
+		Profile:       s.profile,
+		Authenticated: s.authenticated(),
+		Email:         s.email,
+		ConfigPath:    config.ConfigPath(),
+		API:           s.api,
+	}
+}
+
+// profileFlag is the --profile argument that selects profile in hints.
+func profileFlag(profile string) string {
+	if profile == config.DefaultProfile {
+		return ""
+	}
+	return " --profile " + profile
+}
+
+// Auth logs the active profile in to Inkdown, then offers to connect a GitHub
+// account for publishing when the profile has no GitHub token yet. Cancelling
+// ctx or pressing Ctrl-C stops waiting for the GitHub authorization.
+func Auth(ctx context.Context) (*Status, error) {
+	s, err := loadSession()
+	if err != nil {
+		return nil, err
+	}
+
+	status := s.status("none")
+	if s.authenticated() {
+		utils.Printf("✓ Profile '%s' is already authenticated as: %s\n", s.profile, s.email)
+		utils.Printf("  Use 'ink logout%s' to sign out first, or log in with another --profile.\n", profileFlag(s.profile))
+	} else {
+		if err := s.login(); err != nil {
+			return nil, err
+		}
+		status = s.status("login")
+	}
+
+	if s.offerGitHub() {
+		utils.Println()
+		if status.GitHub, err = s.githubLogin(ctx); err != nil {
+			return status, err
+		}
+	}
+	return status, nil
+}
+
+// login asks for the account's email and password and stores the CLI token
+// the API issues for them.
+func (s *session) login() error {
+	cfg := s.cfg
+
+	utils.Printf("Inkdown CLI Authentication (profile: %s)\n", s.profile)
+	utils.Printf("API: %s\n", s.api)
+	utils.Println("─────────────────────────────")
 
 	reader := bufio.NewReader(os.Stdin)
 
 	// Get email
-	fmt.Print("Email: ")
+	utils.Printf("Email: ")
 	email, err := reader.ReadString('\n')
 	if err != nil {
 		return fmt.Errorf("failed to read email: %w", err)
@@ -67,7 +195,7 @@ func Auth() error {
 		return fmt.Errorf("email cannot be empty")
 	}
 
-	fmt.Print("Password: ")
+	utils.Printf("Password: ")
 	password, err := readPassword()
 	if err != nil {
 		return fmt.Errorf("failed to read password: %w", err)
@@ -83,65 +211,122 @@ func Auth() error {
 	}
 	deviceName := fmt.Sprintf("%s-%s-%s", hostname, runtime.GOOS, runtime.GOARCH)
 
-	fmt.Println("\n Authenticating...")
+	utils.Println("\n Authenticating...")
 
-	token, err := login(email, password, deviceName)
+	token, err := login(s.api.URL, email, password, deviceName)
 	if err != nil {
 		return fmt.Errorf("authentication failed: %w", err)
 	}
 
-	cfg.Token = token
-	cfg.Email = email
-	if err := cfg.Save(); err != nil {
+	backend, err := s.store.Set(credentials.Key(s.profile, credentials.InkdownToken), token)
+	if err != nil {
 		return fmt.Errorf("failed to save credentials: %w", err)
 	}
-
-	fmt.Println("\n✓ Authentication successful!")
-	fmt.Printf("  Logged in as: %s\n", email)
-	fmt.Printf("  Config saved to: %s\n", config.ConfigPath())
-
+	p := cfg.Profile(s.profile)
+	p.Email = email
+	if override := config.APIURLOverride(); override != "" {
+		// An endpoint chosen at login sticks to the profile.
+		p.APIURL = strings.TrimRight(override, "/")
+	}
+	if cfg.CurrentProfile == "" {
+		cfg.CurrentProfile = s.profile
+	}
+	if err := cfg.Save(); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+	s.email, s.token = email, token
+
+	utils.Println("\n✓ Authentication successful!")
+	utils.Printf("  Logged in as: %s (profile: %s)\n", email, s.profile)
+	utils.Printf("  Token saved to: %s store (%s)\n", backend.Name(), backend.Location())
+	utils.Printf("  Config saved to: %s\n", config.ConfigPath())
+	if cfg.CurrentProfile != s.profile {
+		utils.Printf("  Use 'ink auth switch %s' to make it the default profile.\n", s.profile)
+	}
 	return nil
 }
 
-func Logout() error {
-	cfg, err := config.Load()
+// ProfileStatus describes a saved profile in `ink auth list`.
+type ProfileStatus struct {
+	Name          string `json:"name"`
+	Active        bool   `json:"active"`
+	Authenticated bool   `json:"authenticated"`
+	Email         string `json:"email,omitempty"`
+	APIURL        string `json:"api_url,omitempty"`
+}
+
+// List reports every saved profile and marks the active one.
+func List() ([]ProfileStatus, error) {
+	s, err := loadSession()
 	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
+		return nil, err
 	}
 
-	if !cfg.IsAuthenticated() {
-		fmt.Println("You are not currently logged in.")
-		return nil
+	names := s.cfg.ProfileNames()
+	if len(names) == 0 {
+		utils.Println("No profiles. Use 'ink auth login' to create one.")
+		return []ProfileStatus{}, nil
 	}
 
-	email := cfg.Email
-	cfg.Token = ""
-	cfg.Email = ""
+	profiles := make([]ProfileStatus, 0, len(names))
+	for _, name := range names {
+		p := s.cfg.Profile(name)
+		_, _, err := s.store.Get(credentials.Key(name, credentials.InkdownToken))
+		profile := ProfileStatus{
+			Name:          name,
+			Active:        name == s.profile,
+			Authenticated: err == nil && p.Email != "",
+			Email:         p.Email,
+			APIURL:        p.APIURL,
+		}
+		profiles = append(profiles, profile)
 
-	if err := cfg.Save(); err != nil {
-		return fmt.Errorf("failed to clear credentials: %w", err)
+		marker := " "
+		if profile.Active {
+			marker = "*"
+		}
+		account := profile.Email
+		if !profile.Authenticated {
+			account = "(not logged in)"
+		}
+		utils.Printf("%s %-12s %s", marker, name, account)
+		if profile.APIURL != "" {
+			utils.Printf("  %s", profile.APIURL)
+		}
+		utils.Println()
 	}
-
-	fmt.Printf("✓ Logged out successfully from: %s\n", email)
-	return nil
+	return profiles, nil
 }
 
-func Whoami() error {
+// Switch makes profile the one commands use when --profile is not given.
+func Switch(profile string) (*Status, error) {
+	if err := config.ValidateProfileName(profile); err != nil {
+		return nil, err
+	}
+
 	cfg, err := config.Load()
 	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	if !cfg.HasProfile(profile) {
+		return nil, fmt.Errorf("unknown profile %q: use 'ink auth login%s' to create it", profile, profileFlag(profile))
 	}
 
-	if !cfg.IsAuthenticated() {
-		fmt.Println("Not authenticated. Use 'inkdown auth' to login.")
-		return nil
+	cfg.CurrentProfile = profile
+	if err := cfg.Save(); err != nil {
+		return nil, fmt.Errorf("failed to save config: %w", err)
 	}
 
-	fmt.Printf("Logged in as: %s\n", cfg.Email)
-	return nil
+	config.SelectProfile(profile)
+	s, err := loadSession()
+	if err != nil {
+		return nil, err
+	}
+	utils.Printf("✓ Switched to profile: %s\n", profile)
+	return s.status("switch"), nil
 }
 
-func login(email, password, deviceName string) (string, error) {
+func login(apiURL, email, password, deviceName string) (string, error) {
 	reqBody := CLILoginRequest{
 		Email:    email,
 		Password: password,
@@ -153,7 +338,7 @@ func login(email, password, deviceName string) (string, error) {
 		return "", fmt.Errorf("failed to encode request: %w", err)
 	}
 
-	req, err := http.NewRequest(http.MethodPost, apiBaseURL+"/cli/login", bytes.NewBuffer(jsonData))
+	req, err := http.NewRequest(http.MethodPost, apiURL+"/cli/login", bytes.NewBuffer(jsonData))
 	if err != nil {
 		return "", fmt.Errorf("failed to create request: %w", err)
 	}
@@ -207,7 +392,7 @@ func readPassword() (string, error) {
 	cmd.Stdin = os.Stdin
 	_ = cmd.Run()
 
-	fmt.Println()
+	utils.Println()
 
 	if err != nil {
 		return "", err
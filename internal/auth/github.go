@@ -0,0 +1,149 @@
+package auth
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"runtime"
+	"strings"
+	"time"
+
+	"inkdown-cli/config"
+	"inkdown-cli/internal/credentials"
+	"inkdown-cli/internal/github"
+	"inkdown-cli/utils"
+)
+
+// GitHubLogin connects a GitHub account to the active profile through the
+// OAuth device flow, so publish finds a token without prompting.
+// Cancelling ctx or pressing Ctrl-C stops waiting for the authorization.
+func GitHubLogin(ctx context.Context) (*Status, error) {
+	s, err := loadSession()
+	if err != nil {
+		return nil, err
+	}
+
+	status := s.status("github-login")
+	status.GitHub, err = s.githubLogin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// offerGitHub asks whether to connect GitHub when the profile has no GitHub
+// token and nothing supplies one from the environment.
+func (s *session) offerGitHub() bool {
+	if !utils.IsInteractive() || config.LoadEnv().GitHubToken != "" {
+		return false
+	}
+	_, _, err := s.store.Get(credentials.Key(s.profile, credentials.GitHubToken))
+	if !errors.Is(err, credentials.ErrNotFound) {
+		return false
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	utils.Prompt("Connect a GitHub account to publish plugins and themes? (Y/n): ")
+	answer, _ := reader.ReadString('\n')
+	answer = strings.TrimSpace(strings.ToLower(answer))
+	return answer == "" || answer == "y" || answer == "yes"
+}
+
+// githubLogin runs the device flow and stores the token under the profile.
+func (s *session) githubLogin(ctx context.Context) (*GitHubCheck, error) {
+	env := config.LoadEnv()
+	client := github.NewClientFromEnv(env, "")
+
+	utils.Printf("GitHub Authentication (profile: %s)\n", s.profile)
+	utils.Println("─────────────────────────────")
+
+	code, err := client.RequestDeviceCode(env.ClientID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start GitHub login: %w", err)
+	}
+
+	utils.Info("To authorize this application, open: %s", code.VerificationURI)
+	utils.Info("And enter the code: %s", code.UserCode)
+	openBrowser(code.VerificationURI)
+
+	// Ctrl-C only stops the wait, so the command can report it cleanly.
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	token, err := client.PollForToken(ctx, env.ClientID, code, countdown())
+	stop()
+	if utils.LogIsTerminal() {
+		utils.Println()
+	}
+	switch {
+	case errors.Is(err, context.Canceled):
+		return nil, fmt.Errorf("GitHub login cancelled")
+	case errors.Is(err, github.ErrAccessDenied):
+		return nil, fmt.Errorf("GitHub login failed: the authorization was denied")
+	case errors.Is(err, github.ErrExpiredToken):
+		return nil, fmt.Errorf("GitHub login failed: the code expired before it was entered, run 'ink auth github%s' again", profileFlag(s.profile))
+	case err != nil:
+		return nil, fmt.Errorf("GitHub login failed: %w", err)
+	}
+
+	login, err := client.WithToken(token).GetGitHubUsername()
+	if err != nil {
+		return nil, fmt.Errorf("GitHub returned a token that does not work: %w", err)
+	}
+
+	backend, err := s.store.Set(credentials.Key(s.profile, credentials.GitHubToken), token)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save credentials: %w", err)
+	}
+	// Make sure the profile is listed even before an Inkdown login.
+	s.cfg.Profile(s.profile)
+	if err := s.cfg.Save(); err != nil {
+		return nil, fmt.Errorf("failed to save config: %w", err)
+	}
+
+	utils.Println("\n✓ GitHub connected!")
+	utils.Printf("  Logged in as: %s (profile: %s)\n", login, s.profile)
+	utils.Printf("  Token saved to: %s store (%s)\n", backend.Name(), backend.Location())
+	return &GitHubCheck{Valid: true, Login: login, Source: "store"}, nil
+}
+
+// countdown reports the time left to enter the device code: redrawn every
+// second on a terminal, once a minute otherwise.
+func countdown() func(remaining time.Duration) {
+	if utils.LogIsTerminal() {
+		return func(remaining time.Duration) {
+			utils.Printf("\r  Waiting for authorization, the code expires in %s ", formatRemaining(remaining))
+		}
+	}
+
+	last := time.Duration(-1)
+	return func(remaining time.Duration) {
+		if minute := remaining.Truncate(time.Minute); minute != last {
+			last = minute
+			utils.Printf("  Waiting for authorization, the code expires in %s\n", formatRemaining(remaining))
+		}
+	}
+}
+
+func formatRemaining(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	return fmt.Sprintf("%d:%02d", int(d.Minutes()), int(d.Seconds())%60)
+}
+
+// openBrowser opens url in the default browser without waiting for it.
+func openBrowser(url string) {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	_ = cmd.Start()
+}
@@ -0,0 +1,160 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"inkdown-cli/config"
+	"inkdown-cli/internal/credentials"
+	"inkdown-cli/internal/github"
+	"inkdown-cli/utils"
+)
+
+// LogoutOptions configures Logout and LogoutAll.
+type LogoutOptions struct {
+	// RevokeGitHub also revokes the OAuth grant of the stored GitHub token,
+	// not only deletes it.
+	RevokeGitHub bool
+}
+
+// Logout revokes the active profile's Inkdown token on the server and
+// deletes it and the profile's GitHub token from the credential store.
+func Logout(opts LogoutOptions) (*Status, error) {
+	s, err := loadSession()
+	if err != nil {
+		return nil, err
+	}
+	return s.logout(opts)
+}
+
+// LogoutAll logs out every saved profile. A profile that fails does not stop
+// the others; the error lists every failure.
+func LogoutAll(opts LogoutOptions) ([]*Status, error) {
+	cfg, store, err := open()
+	if err != nil {
+		return nil, err
+	}
+
+	profiles := cfg.ProfileNames()
+	if !cfg.HasProfile(config.DefaultProfile) {
+		// The default profile can hold a migrated GitHub token without
+		// having been logged in to.
+		profiles = append(profiles, config.DefaultProfile)
+	}
+
+	statuses := []*Status{}
+	var errs []error
+	for _, profile := range profiles {
+		s, err := newSession(cfg, store, profile)
+		if err == nil {
+			var status *Status
+			if status, err = s.logout(opts); err == nil {
+				statuses = append(statuses, status)
+				continue
+			}
+		}
+		errs = append(errs, fmt.Errorf("profile %q: %v", profile, err))
+	}
+	return statuses, errors.Join(errs...)
+}
+
+func (s *session) logout(opts LogoutOptions) (*Status, error) {
+	githubKey := credentials.Key(s.profile, credentials.GitHubToken)
+	githubToken, _, err := s.store.Get(githubKey)
+	if err != nil && !errors.Is(err, credentials.ErrNotFound) {
+		return nil, err
+	}
+
+	if s.token == "" && s.email == "" && githubToken == "" {
+		utils.Printf("You are not currently logged in (profile: %s).\n", s.profile)
+		return s.status("none"), nil
+	}
+
+	status := s.status("logout")
+	status.Authenticated = false
+
+	if s.token != "" {
+		// The local copy goes regardless, so logout works offline.
+		if err := revokeToken(s.api.URL, s.token); err != nil {
+			utils.Warn("Could not revoke the Inkdown token on %s, it stays valid until it expires: %v", s.api.URL, err)
+		} else {
+			status.Revoked = true
+		}
+		if err := s.store.Delete(credentials.Key(s.profile, credentials.InkdownToken)); err != nil {
+			return nil, fmt.Errorf("failed to clear credentials: %w", err)
+		}
+	}
+
+	if githubToken != "" {
+		if opts.RevokeGitHub {
+			status.GitHubRevoked = revokeGitHubGrant(githubToken)
+		}
+		if err := s.store.Delete(githubKey); err != nil {
+			return nil, fmt.Errorf("failed to clear credentials: %w", err)
+		}
+		status.GitHubDeleted = true
+	}
+
+	if s.cfg.HasProfile(s.profile) {
+		// The profile keeps its API URL, if it has one, for the next login.
+		p := s.cfg.Profile(s.profile)
+		p.Email = ""
+		if *p == (config.Profile{}) {
+			s.cfg.RemoveProfile(s.profile)
+		}
+		if err := s.cfg.Save(); err != nil {
+			return nil, fmt.Errorf("failed to clear credentials: %w", err)
+		}
+	}
+
+	account := s.email
+	if account == "" {
+		account = "GitHub"
+	}
+	utils.Printf("✓ Logged out successfully from: %s (profile: %s)\n", account, s.profile)
+	if status.GitHubDeleted {
+		utils.Println("  Deleted the stored GitHub token.")
+	}
+	return status, nil
+}
+
+// revokeGitHubGrant revokes the OAuth grant behind token when the app's
+// client secret is configured, and otherwise tells the user where to do it.
+func revokeGitHubGrant(token string) bool {
+	env := config.LoadEnv()
+	client := github.NewClientFromEnv(env, "")
+
+	if env.ClientSecret == "" {
+		utils.Note("To revoke the GitHub authorization, visit: %s", client.GrantSettingsURL(env.ClientID))
+		return false
+	}
+	if err := client.RevokeGrant(env.ClientID, env.ClientSecret, token); err != nil {
+		utils.Warn("Could not revoke the GitHub authorization: %v", err)
+		utils.Note("Revoke it at: %s", client.GrantSettingsURL(env.ClientID))
+		return false
+	}
+	utils.Println("  Revoked the GitHub authorization.")
+	return true
+}
+
+// revokeToken invalidates token on the server. A token the server already
+// rejects counts as revoked.
+func revokeToken(apiURL, token string) error {
+	req, err := http.NewRequest(http.MethodPost, apiURL+"/cli/revoke", nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to connect to server: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 && resp.StatusCode != http.StatusUnauthorized {
+		return fmt.Errorf("server returned %s", resp.Status)
+	}
+	return nil
+}
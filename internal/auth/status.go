@@ -0,0 +1,199 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"inkdown-cli/config"
+	"inkdown-cli/internal/credentials"
+	"inkdown-cli/internal/github"
+	"inkdown-cli/utils"
+)
+
+// TokenCheck is the server's view of the stored Inkdown token.
+type TokenCheck struct {
+	Valid     bool     `json:"valid"`
+	Prefix    string   `json:"prefix,omitempty"`
+	Scopes    []string `json:"scopes,omitempty"`
+	Device    string   `json:"device,omitempty"`
+	CreatedAt string   `json:"created_at,omitempty"`
+	Error     string   `json:"error,omitempty"`
+}
+
+// GitHubCheck is GitHub's view of the GitHub token in use.
+type GitHubCheck struct {
+	Valid bool   `json:"valid"`
+	Login string `json:"login,omitempty"`
+	// Source is "env" for INK_GITHUB_TOKEN/GITHUB_TOKEN, otherwise "store".
+	Source string `json:"source"`
+	Error  string `json:"error,omitempty"`
+}
+
+// ErrNotAuthenticated is returned, wrapped, by Whoami when the profile has no
+// stored Inkdown token.
+var ErrNotAuthenticated = errors.New("not authenticated")
+
+// errInvalidToken is returned by verifyToken when the server rejects the
+// token.
+var errInvalidToken = errors.New("token is invalid or expired")
+
+// Whoami reports the logged-in account, verifies its tokens with the Inkdown
+// API and GitHub, and shows which credential store backend holds each
+// credential. The error is non-nil when the profile is not logged in
+// (ErrNotAuthenticated) or a stored token cannot be verified; the status is
+// returned either way.
+func Whoami() (*Status, error) {
+	s, err := loadSession()
+	if err != nil {
+		return nil, err
+	}
+
+	status := s.status("none")
+	status.Credentials = s.store.Entries(s.profile)
+
+	var problems []error
+
+	utils.Printf("Profile: %s\n", s.profile)
+	utils.Printf("API: %s\n", s.api)
+	if s.authenticated() {
+		utils.Printf("Logged in as: %s\n", s.email)
+
+		var tokenErr error
+		status.Token, tokenErr = s.checkToken()
+		if tokenErr == nil {
+			utils.Printf("  Token: %s… (device %s, created %s)\n", status.Token.Prefix, status.Token.Device, formatDate(status.Token.CreatedAt))
+			utils.Printf("  Scopes: %s\n", strings.Join(status.Token.Scopes, ", "))
+		} else {
+			status.Authenticated = false
+			utils.Error("  Token: %s", status.Token.Error)
+			problems = append(problems, tokenErr)
+		}
+	} else {
+		utils.Println("Not authenticated")
+		problems = append(problems, fmt.Errorf("%w: use 'ink auth login%s' to login", ErrNotAuthenticated, profileFlag(s.profile)))
+	}
+
+	status.GitHub, err = s.checkGitHub()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case status.GitHub == nil:
+		utils.Println("GitHub: not connected")
+	case status.GitHub.Valid:
+		utils.Printf("GitHub: %s (token from %s)\n", status.GitHub.Login, status.GitHub.Source)
+	default:
+		utils.Error("GitHub: %s", status.GitHub.Error)
+		problems = append(problems, fmt.Errorf("the GitHub token from %s %s", status.GitHub.Source, status.GitHub.Error))
+	}
+
+	utils.Println("\nCredentials:")
+	for _, entry := range status.Credentials {
+		if entry.Backend == "" {
+			utils.Printf("  %-14s not stored\n", entry.Name)
+			continue
+		}
+		utils.Printf("  %-14s %s store (%s)\n", entry.Name, entry.Backend, entry.Location)
+	}
+
+	if len(problems) > 0 {
+		return status, errors.Join(problems...)
+	}
+	return status, nil
+}
+
+// checkToken asks the API about the session's token. The error explains a
+// failed check.
+func (s *session) checkToken() (*TokenCheck, error) {
+	info, err := verifyToken(s.api.URL, s.token)
+	if errors.Is(err, errInvalidToken) {
+		return &TokenCheck{Error: "invalid or expired"},
+			fmt.Errorf("the Inkdown token of profile %q is invalid or expired: run 'ink auth login%s' again", s.profile, profileFlag(s.profile))
+	}
+	if err != nil {
+		return &TokenCheck{Error: fmt.Sprintf("could not be verified: %v", err)},
+			fmt.Errorf("the Inkdown token of profile %q could not be verified: %v", s.profile, err)
+	}
+	return &TokenCheck{
+		Valid:     true,
+		Prefix:    info.TokenPrefix,
+		Scopes:    info.Scopes,
+		Device:    info.Name,
+		CreatedAt: info.CreatedAt,
+	}, nil
+}
+
+// checkGitHub looks up the GitHub account of the token publish would use:
+// the environment's, then the profile's stored one. It returns nil when
+// there is neither.
+func (s *session) checkGitHub() (*GitHubCheck, error) {
+	env := config.LoadEnv()
+	check := &GitHubCheck{Source: "env", Valid: true}
+	token := env.GitHubToken
+
+	if token == "" {
+		var err error
+		token, _, err = s.store.Get(credentials.Key(s.profile, credentials.GitHubToken))
+		if errors.Is(err, credentials.ErrNotFound) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		check.Source = "store"
+	}
+
+	login, err := github.NewClientFromEnv(env, token).GetGitHubUsername()
+	if err != nil {
+		check.Valid = false
+		check.Error = fmt.Sprintf("could not be verified: %v", err)
+		return check, nil
+	}
+	check.Login = login
+	return check, nil
+}
+
+// verifyToken describes token as the API sees it.
+func verifyToken(apiURL, token string) (*CLIToken, error) {
+	req, err := http.NewRequest(http.MethodGet, apiURL+"/cli/me", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to server: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return nil, errInvalidToken
+	}
+
+	var result CLILoginResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response (HTTP %d): %w", resp.StatusCode, err)
+	}
+	if !result.Success {
+		errMsg := result.Error
+		if errMsg == "" {
+			errMsg = fmt.Sprintf("HTTP %d", resp.StatusCode)
+		}
+		return nil, fmt.Errorf("%s", errMsg)
+	}
+	return &result.Data, nil
+}
+
+// formatDate shortens an RFC 3339 timestamp to its local date.
+func formatDate(value string) string {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return value
+	}
+	return t.Local().Format("2006-01-02")
+}
@@ -0,0 +1,226 @@
+// Package build bundles plugin sources with esbuild, in-process, using the
+// same settings as the template's esbuild.config.mjs.
+package build
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"inkdown-cli/utils"
+
+	"github.com/evanw/esbuild/pkg/api"
+)
+
+// Outfile is the bundle the app loads, relative to the plugin directory.
+const Outfile = "main.js"
+
+const banner = `/*
+THIS IS A GENERATED/BUNDLED FILE BY ESBUILD
+if you want to view the source, please visit the github repository of this plugin
+*/
+`
+
+// entryPoints are tried in order, relative to the plugin directory.
+var entryPoints = []string{"src/main.ts", "src/main.js"}
+
+// nodeBuiltins are left external, as builtin-modules does for the template.
+var nodeBuiltins = []string{
+	"assert", "async_hooks", "buffer", "child_process", "cluster", "console",
+	"constants", "crypto", "dgram", "diagnostics_channel", "dns", "domain",
+	"events", "fs", "http", "http2", "https", "inspector", "module", "net",
+	"os", "path", "perf_hooks", "process", "punycode", "querystring",
+	"readline", "repl", "stream", "string_decoder", "sys", "timers", "tls",
+	"trace_events", "tty", "url", "util", "v8", "vm", "wasi", "worker_threads",
+	"zlib",
+}
+
+type Options struct {
+	// Production minifies the bundle and drops the inline source map. The
+	// sources are type-checked first, as by the template's build script.
+	Production bool
+}
+
+// EntryPoint returns the plugin entry point in dir.
+func EntryPoint(dir string) (string, error) {
+	for _, entry := range entryPoints {
+		if _, err := os.Stat(filepath.Join(dir, entry)); err == nil {
+			return entry, nil
+		}
+	}
+	return "", fmt.Errorf("no entry point found: expected %s", strings.Join(entryPoints, " or "))
+}
+
+// BuildOptions returns the esbuild options for the plugin in dir.
+func BuildOptions(dir string, opts Options) (api.BuildOptions, error) {
+	entry, err := EntryPoint(dir)
+	if err != nil {
+		return api.BuildOptions{}, err
+	}
+
+	external := []string{"inkdown-api"}
+	for _, name := range nodeBuiltins {
+		external = append(external, name, "node:"+name)
+	}
+
+	sourcemap := api.SourceMapInline
+	if opts.Production {
+		sourcemap = api.SourceMapNone
+	}
+
+	return api.BuildOptions{
+		AbsWorkingDir:     dir,
+		EntryPoints:       []string{entry},
+		Outfile:           Outfile,
+		Bundle:            true,
+		Write:             true,
+		External:          external,
+		Format:            api.FormatCommonJS,
+		Target:            api.ES2018,
+		Banner:            map[string]string{"js": banner},
+		Sourcemap:         sourcemap,
+		TreeShaking:       api.TreeShakingTrue,
+		MinifyWhitespace:  opts.Production,
+		MinifyIdentifiers: opts.Production,
+		MinifySyntax:      opts.Production,
+		LogLevel:          api.LogLevelSilent,
+	}, nil
+}
+
+// Bundle builds the plugin in dir to main.js.
+func Bundle(dir string, opts Options) error {
+	buildOpts, err := BuildOptions(dir, opts)
+	if err != nil {
+		return err
+	}
+
+	if opts.Production {
+		if err := TypeCheck(dir); err != nil {
+			return err
+		}
+	}
+
+	utils.Info("Bundling %s with esbuild...", buildOpts.EntryPoints[0])
+
+	result := api.Build(buildOpts)
+	if err := Messages(result.Warnings, result.Errors); err != nil {
+		return err
+	}
+
+	utils.Success("Built %s", Outfile)
+	return nil
+}
+
+// TypeCheck runs the plugin's own TypeScript compiler without emitting, like
+// the "tsc -noEmit -skipLibCheck" of the template's build script. esbuild
+// only strips types. Plugins without a tsconfig.json or a local typescript
+// install are not checked.
+func TypeCheck(dir string) error {
+	if _, err := os.Stat(filepath.Join(dir, "tsconfig.json")); err != nil {
+		return nil
+	}
+	tsc := filepath.Join(dir, "node_modules", ".bin", "tsc")
+	if runtime.GOOS == "windows" {
+		tsc += ".cmd"
+	}
+	if _, err := os.Stat(tsc); err != nil {
+		utils.Warn("Skipping the type check: typescript is not installed in node_modules.")
+		return nil
+	}
+
+	utils.Info("Type-checking with tsc...")
+	cmd := exec.Command(tsc, "-noEmit", "-skipLibCheck")
+	cmd.Dir = dir
+	cmd.Stdout = utils.LogWriter()
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("type check failed: %v", err)
+	}
+	return nil
+}
+
+// Messages prints esbuild warnings and errors and returns an error when
+// there are any errors.
+func Messages(warnings, errors []api.Message) error {
+	for _, text := range api.FormatMessages(warnings, api.FormatMessagesOptions{Kind: api.WarningMessage}) {
+		utils.Printf("%s", text)
+	}
+	if len(errors) == 0 {
+		return nil
+	}
+	for _, text := range api.FormatMessages(errors, api.FormatMessagesOptions{Kind: api.ErrorMessage}) {
+		utils.Printf("%s", text)
+	}
+	return fmt.Errorf("build failed with %d error(s)", len(errors))
+}
+
+// Script builds the plugin with the "build" script of its package.json,
+// installing dependencies with the detected package manager first. pm
+// overrides the detection when not empty.
+func Script(dir string, pm string) error {
+	if _, err := os.Stat(filepath.Join(dir, "package.json")); err != nil {
+		return fmt.Errorf("no package.json found in %s", dir)
+	}
+
+	detected, err := DetectPackageManager(dir, pm)
+	if err != nil {
+		return err
+	}
+	command, err := detected.command()
+	if err != nil {
+		return err
+	}
+
+	if detected.Lockfile == "" {
+		utils.Warn("No %s lockfile found; dependencies are not pinned.", detected.Name)
+	}
+
+	for _, args := range [][]string{detected.installArgs(), {"run", "build"}} {
+		if err := detected.run(command, dir, args); err != nil {
+			return err
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, Outfile)); os.IsNotExist(err) {
+		return fmt.Errorf("build completed but '%s' was not found", Outfile)
+	}
+	utils.Success("Built %s", Outfile)
+	return nil
+}
+
+// NeedsInstall reports whether the plugin in dir declares dependencies that
+// are not installed. Both kinds count: the template keeps the packages the
+// bundler resolves, such as inkdown-api and tslib, in devDependencies.
+func NeedsInstall(dir string) bool {
+	data, err := os.ReadFile(filepath.Join(dir, "package.json"))
+	if err != nil {
+		return false
+	}
+	var pkg struct {
+		Dependencies    map[string]string `json:"dependencies"`
+		DevDependencies map[string]string `json:"devDependencies"`
+	}
+	if json.Unmarshal(data, &pkg) != nil || len(pkg.Dependencies)+len(pkg.DevDependencies) == 0 {
+		return false
+	}
+	_, err = os.Stat(filepath.Join(dir, "node_modules"))
+	return os.IsNotExist(err)
+}
+
+// Install installs the dependencies of the plugin in dir with the detected
+// package manager, frozen to the lockfile when there is one.
+func Install(dir string, pm string) error {
+	detected, err := DetectPackageManager(dir, pm)
+	if err != nil {
+		return err
+	}
+	command, err := detected.command()
+	if err != nil {
+		return err
+	}
+	return detected.run(command, dir, detected.installArgs())
+}
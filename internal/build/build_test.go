@@ -0,0 +1,56 @@
+package build
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNeedsInstall(t *testing.T) {
+	tests := []struct {
+		name        string
+		packageJSON string
+		nodeModules bool
+		want        bool
+	}{
+		{"no package.json", "", false, false},
+		{"no dependencies", `{"name": "p", "dependencies": {}}`, false, false},
+		{"dependencies", `{"dependencies": {"a": "1"}}`, false, true},
+		{"only devDependencies", `{"devDependencies": {"inkdown-api": "^0.1.1"}, "dependencies": {}}`, false, true},
+		{"installed", `{"devDependencies": {"inkdown-api": "^0.1.1"}}`, true, false},
+		{"invalid package.json", `{`, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if tt.packageJSON != "" {
+				if err := os.WriteFile(filepath.Join(dir, "package.json"), []byte(tt.packageJSON), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tt.nodeModules {
+				if err := os.Mkdir(filepath.Join(dir, "node_modules"), 0755); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if got := NeedsInstall(dir); got != tt.want {
+				t.Errorf("NeedsInstall = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTypeCheckSkipsWithoutTypeScript(t *testing.T) {
+	dir := t.TempDir()
+	if err := TypeCheck(dir); err != nil {
+		t.Errorf("TypeCheck without tsconfig.json error: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "tsconfig.json"), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := TypeCheck(dir); err != nil {
+		t.Errorf("TypeCheck without a local typescript error: %v", err)
+	}
+}
@@ -0,0 +1,185 @@
+package build
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"inkdown-cli/config"
+	"inkdown-cli/utils"
+)
+
+// PackageManager describes how to install dependencies and run scripts with
+// one Node.js package manager.
+type PackageManager struct {
+	Name      string
+	Lockfiles []string
+	// FrozenInstall installs exactly what the lockfile pins.
+	FrozenInstall []string
+	// Install is used when the project has no lockfile.
+	Install []string
+}
+
+// Managers are listed in lockfile detection order.
+var Managers = []*PackageManager{
+	{
+		Name:          "bun",
+		Lockfiles:     []string{"bun.lock", "bun.lockb"},
+		FrozenInstall: []string{"install", "--frozen-lockfile"},
+		Install:       []string{"install"},
+	},
+	{
+		Name:          "pnpm",
+		Lockfiles:     []string{"pnpm-lock.yaml"},
+		FrozenInstall: []string{"install", "--frozen-lockfile"},
+		Install:       []string{"install"},
+	},
+	{
+		Name:          "yarn",
+		Lockfiles:     []string{"yarn.lock"},
+		FrozenInstall: []string{"install", "--frozen-lockfile"},
+		Install:       []string{"install"},
+	},
+	{
+		Name:          "npm",
+		Lockfiles:     []string{"package-lock.json", "npm-shrinkwrap.json"},
+		FrozenInstall: []string{"ci"},
+		Install:       []string{"install"},
+	},
+}
+
+// DefaultManager is used when nothing in the project names one.
+const DefaultManager = "npm"
+
+// Detected is the package manager chosen for a project and why.
+type Detected struct {
+	*PackageManager
+	// Source explains the choice, e.g. "--pm" or "yarn.lock".
+	Source   string
+	Lockfile string
+}
+
+func findManager(name string) (*PackageManager, error) {
+	for _, pm := range Managers {
+		if pm.Name == name {
+			return pm, nil
+		}
+	}
+	names := make([]string, len(Managers))
+	for i, pm := range Managers {
+		names[i] = pm.Name
+	}
+	return nil, fmt.Errorf("unknown package manager %q: must be one of %s", name, strings.Join(names, ", "))
+}
+
+// DetectPackageManager picks the package manager for the project in dir from,
+// in order: override (the --pm flag), .inkrc.json, the packageManager field
+// of package.json, lockfiles, and finally npm.
+func DetectPackageManager(dir string, override string) (*Detected, error) {
+	detected, err := choosePackageManager(dir, override)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, lockfile := range detected.Lockfiles {
+		if _, err := os.Stat(filepath.Join(dir, lockfile)); err == nil {
+			detected.Lockfile = lockfile
+			break
+		}
+	}
+	return detected, nil
+}
+
+func choosePackageManager(dir string, override string) (*Detected, error) {
+	if override != "" {
+		pm, err := findManager(override)
+		if err != nil {
+			return nil, err
+		}
+		return &Detected{PackageManager: pm, Source: "--pm"}, nil
+	}
+
+	project, err := config.LoadProject(dir)
+	if err != nil {
+		return nil, err
+	}
+	if project.Build.PackageManager != "" {
+		pm, err := findManager(project.Build.PackageManager)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", config.ProjectFile, err)
+		}
+		return &Detected{PackageManager: pm, Source: config.ProjectFile}, nil
+	}
+
+	if data, err := os.ReadFile(filepath.Join(dir, "package.json")); err == nil {
+		var pkg struct {
+			PackageManager string `json:"packageManager"`
+		}
+		if json.Unmarshal(data, &pkg) == nil && pkg.PackageManager != "" {
+			// e.g. "pnpm@9.1.0+sha512..."
+			name, _, _ := strings.Cut(pkg.PackageManager, "@")
+			pm, err := findManager(name)
+			if err != nil {
+				return nil, fmt.Errorf("package.json packageManager: %v", err)
+			}
+			return &Detected{PackageManager: pm, Source: "package.json packageManager"}, nil
+		}
+	}
+
+	var found []*Detected
+	for _, pm := range Managers {
+		for _, lockfile := range pm.Lockfiles {
+			if _, err := os.Stat(filepath.Join(dir, lockfile)); err == nil {
+				found = append(found, &Detected{PackageManager: pm, Source: lockfile})
+				break
+			}
+		}
+	}
+	if len(found) > 1 {
+		sources := make([]string, len(found))
+		for i, d := range found {
+			sources[i] = d.Source
+		}
+		utils.Warn("Found several lockfiles (%s); using %s. Pass --pm to choose another.", strings.Join(sources, ", "), found[0].Name)
+	}
+	if len(found) > 0 {
+		return found[0], nil
+	}
+
+	pm, _ := findManager(DefaultManager)
+	return &Detected{PackageManager: pm, Source: "default"}, nil
+}
+
+// command returns the path of the package manager executable, or a clear
+// error when it is not installed.
+func (d *Detected) command() (string, error) {
+	path, err := exec.LookPath(d.Name)
+	if err != nil {
+		return "", fmt.Errorf("%s was selected (from %s) but is not on PATH: install it or choose another package manager with --pm", d.Name, d.Source)
+	}
+	return path, nil
+}
+
+// installArgs uses a frozen install when the project has a lockfile.
+func (d *Detected) installArgs() []string {
+	if d.Lockfile == "" {
+		return d.Install
+	}
+	return d.FrozenInstall
+}
+
+func (d *Detected) run(command, dir string, args []string) error {
+	utils.Info("Running '%s %s'...", d.Name, strings.Join(args, " "))
+
+	cmd := exec.Command(command, args...)
+	cmd.Dir = dir
+	cmd.Stdout = utils.LogWriter()
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to run '%s %s': %v", d.Name, strings.Join(args, " "), err)
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package build
+
+import (
+	"context"
+	"fmt"
+
+	"inkdown-cli/utils"
+
+	"github.com/evanw/esbuild/pkg/api"
+)
+
+// Watch rebuilds the plugin in dir whenever its sources change, until ctx is
+// done. onBuild runs after every successful build, the first included; build
+// errors are printed and watching continues.
+func Watch(ctx context.Context, dir string, opts Options, onBuild func() error) error {
+	buildOpts, err := BuildOptions(dir, opts)
+	if err != nil {
+		return err
+	}
+
+	buildOpts.Plugins = append(buildOpts.Plugins, api.Plugin{
+		Name: "ink-watch",
+		Setup: func(b api.PluginBuild) {
+			b.OnEnd(func(result *api.BuildResult) (api.OnEndResult, error) {
+				if err := Messages(result.Warnings, result.Errors); err != nil {
+					utils.Error("%v, waiting for changes...", err)
+					return api.OnEndResult{}, nil
+				}
+				utils.Success("Built %s", Outfile)
+				if err := onBuild(); err != nil {
+					utils.Error("%v", err)
+				}
+				return api.OnEndResult{}, nil
+			})
+		},
+	})
+
+	esbuildCtx, ctxErr := api.Context(buildOpts)
+	if ctxErr != nil {
+		if err := Messages(nil, ctxErr.Errors); err != nil {
+			return err
+		}
+		return fmt.Errorf("could not start esbuild")
+	}
+	defer esbuildCtx.Dispose()
+
+	if err := esbuildCtx.Watch(api.WatchOptions{}); err != nil {
+		return fmt.Errorf("could not watch %s: %v", dir, err)
+	}
+
+	<-ctx.Done()
+	return nil
+}
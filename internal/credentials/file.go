@@ -0,0 +1,224 @@
+package credentials
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"github.com/adrg/xdg"
+)
+
+const (
+	fileVersion = 1
+	keyInfo     = "inkdown-cli credentials v1"
+)
+
+// fileBackend keeps secrets in a 0600 JSON file, each sealed with AES-256-GCM
+// under a key derived from the machine id and the user account. The key is
+// not stored, so a copy of the file is useless on another machine or
+// account; it does not protect against other programs run by the same user.
+type fileBackend struct {
+	path string
+}
+
+type credentialFile struct {
+	Version int    `json:"version"`
+	Salt    string `json:"salt"`
+	// Secrets maps credential names to base64 nonce||ciphertext.
+	Secrets map[string]string `json:"secrets"`
+}
+
+func newFileBackend() (*fileBackend, error) {
+	return &fileBackend{path: filepath.Join(xdg.ConfigHome, "ink", "credentials.json")}, nil
+}
+
+func (f *fileBackend) Name() string     { return "file" }
+func (f *fileBackend) Location() string { return f.path }
+
+func (f *fileBackend) Get(name string) (string, error) {
+	cf, err := f.load()
+	if err != nil {
+		return "", err
+	}
+	sealed, ok := cf.Secrets[name]
+	if !ok {
+		return "", ErrNotFound
+	}
+
+	aead, err := cf.cipher()
+	if err != nil {
+		return "", err
+	}
+	data, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil || len(data) < aead.NonceSize() {
+		return "", fmt.Errorf("%s is corrupted", f.path)
+	}
+	plain, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], []byte(name))
+	if err != nil {
+		return "", fmt.Errorf("could not decrypt %s from %s: it was written on another machine or user account; log in again", name, f.path)
+	}
+	return string(plain), nil
+}
+
+func (f *fileBackend) Set(name, secret string) error {
+	cf, err := f.load()
+	if err != nil {
+		return err
+	}
+
+	aead, err := cf.cipher()
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	cf.Secrets[name] = base64.StdEncoding.EncodeToString(aead.Seal(nonce, nonce, []byte(secret), []byte(name)))
+	return f.save(cf)
+}
+
+func (f *fileBackend) Delete(name string) error {
+	cf, err := f.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := cf.Secrets[name]; !ok {
+		return nil
+	}
+	delete(cf.Secrets, name)
+	return f.save(cf)
+}
+
+// load reads the credential file, or returns a new one with a fresh salt
+// when it does not exist.
+func (f *fileBackend) load() (*credentialFile, error) {
+	data, err := os.ReadFile(f.path)
+	if os.IsNotExist(err) {
+		salt := make([]byte, 16)
+		if _, err := rand.Read(salt); err != nil {
+			return nil, err
+		}
+		return &credentialFile{
+			Version: fileVersion,
+			Salt:    base64.StdEncoding.EncodeToString(salt),
+			Secrets: map[string]string{},
+		}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var cf credentialFile
+	if err := json.Unmarshal(data, &cf); err != nil {
+		return nil, fmt.Errorf("invalid %s: %v", f.path, err)
+	}
+	if cf.Version != fileVersion {
+		return nil, fmt.Errorf("%s has unsupported version %d", f.path, cf.Version)
+	}
+	if cf.Secrets == nil {
+		cf.Secrets = map[string]string{}
+	}
+	return &cf, nil
+}
+
+// save writes the file atomically, readable by the owner only.
+func (f *fileBackend) save(cf *credentialFile) error {
+	dir := filepath.Dir(f.path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(cf, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, ".credentials-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(0600); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), f.path)
+}
+
+func (cf *credentialFile) cipher() (cipher.AEAD, error) {
+	salt, err := base64.StdEncoding.DecodeString(cf.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid credential file salt: %v", err)
+	}
+	key, err := hkdf.Key(sha256.New, []byte(machineSecret()), salt, keyInfo, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// machineSecret identifies this machine and user account. It is stable
+// across runs but not secret from the user themselves.
+func machineSecret() string {
+	account := ""
+	if u, err := user.Current(); err == nil {
+		account = u.Uid + ":" + u.Username
+	}
+	return machineID() + "\x00" + account
+}
+
+var (
+	ioregUUID   = regexp.MustCompile(`"IOPlatformUUID" = "([^"]+)"`)
+	machineGUID = regexp.MustCompile(`MachineGuid\s+REG_SZ\s+(\S+)`)
+)
+
+// machineID returns the OS installation id, falling back to the hostname.
+func machineID() string {
+	switch runtime.GOOS {
+	case "darwin":
+		if out, err := exec.Command("ioreg", "-rd1", "-c", "IOPlatformExpertDevice").Output(); err == nil {
+			if m := ioregUUID.FindSubmatch(out); m != nil {
+				return string(m[1])
+			}
+		}
+	case "windows":
+		if out, err := exec.Command("reg", "query", `HKLM\SOFTWARE\Microsoft\Cryptography`, "/v", "MachineGuid").Output(); err == nil {
+			if m := machineGUID.FindSubmatch(out); m != nil {
+				return string(m[1])
+			}
+		}
+	default:
+		for _, path := range []string{"/etc/machine-id", "/var/lib/dbus/machine-id"} {
+			if b, err := os.ReadFile(path); err == nil && len(strings.TrimSpace(string(b))) > 0 {
+				return strings.TrimSpace(string(b))
+			}
+		}
+	}
+
+	hostname, _ := os.Hostname()
+	return hostname
+}
@@ -0,0 +1,151 @@
+package credentials
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func newTestFileBackend(t *testing.T) *fileBackend {
+	t.Helper()
+	return &fileBackend{path: filepath.Join(t.TempDir(), "ink", "credentials.json")}
+}
+
+func TestFileBackendRoundTrip(t *testing.T) {
+	f := newTestFileBackend(t)
+
+	if _, err := f.Get(InkdownToken); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get before Set error = %v, want ErrNotFound", err)
+	}
+
+	secrets := map[string]string{
+		InkdownToken:          "ink_0123456789abcdef",
+		"work/" + GitHubToken: "ghp_secret",
+	}
+	for name, secret := range secrets {
+		if err := f.Set(name, secret); err != nil {
+			t.Fatalf("Set(%q) error: %v", name, err)
+		}
+	}
+	for name, want := range secrets {
+		got, err := f.Get(name)
+		if err != nil || got != want {
+			t.Errorf("Get(%q) = %q, %v, want %q", name, got, err, want)
+		}
+	}
+
+	if err := f.Delete(InkdownToken); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Get(InkdownToken); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get after Delete error = %v, want ErrNotFound", err)
+	}
+	if err := f.Delete(InkdownToken); err != nil {
+		t.Errorf("Delete of a missing credential error: %v", err)
+	}
+}
+
+func TestFileBackendEncryptsSecrets(t *testing.T) {
+	f := newTestFileBackend(t)
+	if err := f.Set(InkdownToken, "ink_plaintext_secret"); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "ink_plaintext_secret") {
+		t.Errorf("credential file contains the secret in plaintext:\n%s", data)
+	}
+
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(f.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if perm := info.Mode().Perm(); perm != 0600 {
+			t.Errorf("credential file mode = %o, want 600", perm)
+		}
+	}
+
+	// Sealing twice uses a fresh nonce.
+	var before credentialFile
+	if err := json.Unmarshal(data, &before); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Set(InkdownToken, "ink_plaintext_secret"); err != nil {
+		t.Fatal(err)
+	}
+	after, err := f.load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after.Secrets[InkdownToken] == before.Secrets[InkdownToken] {
+		t.Error("re-sealing the same secret produced the same ciphertext")
+	}
+	if after.Salt != before.Salt {
+		t.Error("the salt changed between writes")
+	}
+}
+
+func TestFileBackendRejectsTampering(t *testing.T) {
+	tests := []struct {
+		name   string
+		tamper func(cf *credentialFile)
+	}{
+		{"swapped between names", func(cf *credentialFile) {
+			cf.Secrets[InkdownToken], cf.Secrets[GitHubToken] = cf.Secrets[GitHubToken], cf.Secrets[InkdownToken]
+		}},
+		{"different salt", func(cf *credentialFile) {
+			cf.Salt = "AAAAAAAAAAAAAAAAAAAAAA=="
+		}},
+		{"truncated", func(cf *credentialFile) {
+			cf.Secrets[InkdownToken] = "AAAA"
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newTestFileBackend(t)
+			if err := f.Set(InkdownToken, "ink_token"); err != nil {
+				t.Fatal(err)
+			}
+			if err := f.Set(GitHubToken, "ghp_token"); err != nil {
+				t.Fatal(err)
+			}
+
+			cf, err := f.load()
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.tamper(cf)
+			if err := f.save(cf); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := f.Get(InkdownToken)
+			if err == nil || errors.Is(err, ErrNotFound) {
+				t.Errorf("Get of a tampered secret = %q, %v, want a decryption error", got, err)
+			}
+		})
+	}
+}
+
+func TestFileBackendRejectsUnknownVersion(t *testing.T) {
+	f := newTestFileBackend(t)
+	if err := os.MkdirAll(filepath.Dir(f.path), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(f.path, []byte(`{"version": 2, "salt": "", "secrets": {}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := f.Get(InkdownToken); err == nil || errors.Is(err, ErrNotFound) {
+		t.Errorf("Get error = %v, want an unsupported version error", err)
+	}
+}
@@ -0,0 +1,79 @@
+package credentials
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+const keyringService = "inkdown-cli"
+
+// keyringBackend stores secrets in the Secret Service (GNOME Keyring,
+// KWallet) through libsecret's secret-tool.
+type keyringBackend struct{}
+
+// keyringAvailable reports whether secret-tool is installed and a session
+// bus to reach the Secret Service is running.
+func keyringAvailable() bool {
+	if runtime.GOOS != "linux" || os.Getenv("DBUS_SESSION_BUS_ADDRESS") == "" {
+		return false
+	}
+	_, err := exec.LookPath("secret-tool")
+	return err == nil
+}
+
+func (keyringBackend) Name() string     { return "keyring" }
+func (keyringBackend) Location() string { return "Secret Service (service " + keyringService + ")" }
+
+func (keyringBackend) Get(name string) (string, error) {
+	out, err := secretTool(nil, "lookup", "service", keyringService, "credential", name)
+	var exit *exec.ExitError
+	if errors.As(err, &exit) && exit.ExitCode() == 1 && len(out) == 0 && len(exit.Stderr) == 0 {
+		// secret-tool exits 1 silently when nothing matches.
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(string(out), "\n"), nil
+}
+
+func (keyringBackend) Set(name, secret string) error {
+	_, err := secretTool(strings.NewReader(secret), "store",
+		"--label", "Inkdown CLI: "+name,
+		"service", keyringService, "credential", name)
+	return err
+}
+
+func (keyringBackend) Delete(name string) error {
+	_, err := secretTool(nil, "clear", "service", keyringService, "credential", name)
+	var exit *exec.ExitError
+	if errors.As(err, &exit) && len(exit.Stderr) == 0 {
+		// clear exits non-zero when nothing matched.
+		return nil
+	}
+	return err
+}
+
+func secretTool(stdin *strings.Reader, args ...string) ([]byte, error) {
+	cmd := exec.Command("secret-tool", args...)
+	if stdin != nil {
+		cmd.Stdin = stdin
+	}
+
+	// Output captures stderr into the returned *exec.ExitError.
+	out, err := cmd.Output()
+	if err != nil {
+		var exit *exec.ExitError
+		if errors.As(err, &exit) && len(bytes.TrimSpace(exit.Stderr)) > 0 {
+			msg := string(bytes.TrimSpace(exit.Stderr))
+			return out, fmt.Errorf("secret-tool %s: %s: %w", args[0], msg, err)
+		}
+		return out, fmt.Errorf("secret-tool %s: %w", args[0], err)
+	}
+	return out, nil
+}
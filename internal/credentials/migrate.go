@@ -0,0 +1,62 @@
+package credentials
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"inkdown-cli/config"
+	"inkdown-cli/utils"
+)
+
+// legacyGitHubTokenPath is where versions before the credential store saved
+// the GitHub token in plaintext.
+func legacyGitHubTokenPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".community-cli", "token")
+}
+
+// migrate moves plaintext tokens written by earlier versions into s: the API
+// token in config.json and the GitHub token in ~/.community-cli/token, both
+// of which become the default profile's. A failed move leaves the old copy
+// in place and is retried next time.
+func (s *Store) migrate() {
+	if cfg, err := config.Load(); err == nil && cfg.Token != "" {
+		if b, err := s.Set(Key(config.DefaultProfile, InkdownToken), cfg.Token); err != nil {
+			utils.Warn("Could not move the Inkdown token out of %s: %v", config.ConfigPath(), err)
+		} else {
+			cfg.Token = ""
+			if err := cfg.Save(); err != nil {
+				utils.Warn("Could not remove the plaintext token from %s: %v", config.ConfigPath(), err)
+			} else {
+				utils.Note("Moved the Inkdown token from %s to the %s credential store", config.ConfigPath(), b.Name())
+			}
+		}
+	}
+
+	path := legacyGitHubTokenPath()
+	if path == "" {
+		return
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	if token := strings.TrimSpace(string(data)); token != "" {
+		b, err := s.Set(Key(config.DefaultProfile, GitHubToken), token)
+		if err != nil {
+			utils.Warn("Could not move the GitHub token out of %s: %v", path, err)
+			return
+		}
+		utils.Note("Moved the GitHub token from %s to the %s credential store", path, b.Name())
+	}
+	if err := os.Remove(path); err != nil {
+		utils.Warn("Could not remove the plaintext GitHub token %s: %v", path, err)
+		return
+	}
+	// Only succeeds when nothing else was kept there.
+	_ = os.Remove(filepath.Dir(path))
+}
@@ -0,0 +1,156 @@
+// Package credentials keeps the CLI's secrets — the Inkdown API token and the
+// GitHub token — out of plaintext files. Secrets go to the system keyring
+// when one is available and to an encrypted file otherwise.
+package credentials
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"inkdown-cli/config"
+	"inkdown-cli/utils"
+)
+
+// Names of the credentials the CLI stores.
+const (
+	InkdownToken = "inkdown-token"
+	GitHubToken  = "github-token"
+)
+
+// Names lists every credential, in the order status output shows them.
+var Names = []string{InkdownToken, GitHubToken}
+
+// Key returns the store key of credential name for profile. The default
+// profile uses the bare name, as credentials were stored before profiles.
+func Key(profile, name string) string {
+	if profile == config.DefaultProfile {
+		return name
+	}
+	return profile + "/" + name
+}
+
+// ErrNotFound is returned when no backend holds a credential.
+var ErrNotFound = errors.New("credential not found")
+
+// Backend is a place secrets can be kept.
+type Backend interface {
+	// Name identifies the backend in status output: "keyring" or "file".
+	Name() string
+	// Location describes where the backend keeps secrets.
+	Location() string
+	// Get returns ErrNotFound when the backend does not hold key.
+	Get(key string) (string, error)
+	Set(key, secret string) error
+	// Delete succeeds when the backend does not hold key.
+	Delete(key string) error
+}
+
+// Store reads credentials from every backend and writes them to the
+// preferred one, so a secret moves to the keyring once it becomes available.
+type Store struct {
+	backends []Backend // preferred first
+}
+
+// Entry reports where a credential is stored. Backend is empty when it is
+// not stored anywhere.
+type Entry struct {
+	Name     string `json:"name"`
+	Backend  string `json:"backend,omitempty"`
+	Location string `json:"location,omitempty"`
+}
+
+// Open returns the store and moves tokens left in plaintext by earlier
+// versions into it. INK_CREDENTIAL_STORE=file or keyring forces a backend.
+func Open() (*Store, error) {
+	file, err := newFileBackend()
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Store{}
+	switch forced := os.Getenv("INK_CREDENTIAL_STORE"); forced {
+	case "":
+		if keyringAvailable() {
+			s.backends = append(s.backends, keyringBackend{})
+		}
+		s.backends = append(s.backends, file)
+	case "keyring":
+		if !keyringAvailable() {
+			return nil, fmt.Errorf("INK_CREDENTIAL_STORE=keyring but no keyring is available: install secret-tool (libsecret) and run inside a desktop session")
+		}
+		s.backends = []Backend{keyringBackend{}}
+	case "file":
+		s.backends = []Backend{file}
+	default:
+		return nil, fmt.Errorf("invalid INK_CREDENTIAL_STORE %q: must be keyring or file", forced)
+	}
+
+	s.migrate()
+	return s, nil
+}
+
+// Get returns the secret stored under key and the backend holding it.
+func (s *Store) Get(key string) (string, Backend, error) {
+	for _, b := range s.backends {
+		secret, err := b.Get(key)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return "", nil, fmt.Errorf("could not read %s from the %s store: %v", key, b.Name(), err)
+		}
+		return secret, b, nil
+	}
+	return "", nil, ErrNotFound
+}
+
+// Set stores secret under key in the first backend that accepts it and
+// removes copies from the others. It returns the backend used.
+func (s *Store) Set(key, secret string) (Backend, error) {
+	var errs []error
+	for i, b := range s.backends {
+		if err := b.Set(key, secret); err != nil {
+			errs = append(errs, fmt.Errorf("%s store: %v", b.Name(), err))
+			if i+1 < len(s.backends) {
+				utils.Warn("Could not save %s to the %s store, falling back to the %s store: %v", key, b.Name(), s.backends[i+1].Name(), err)
+			}
+			continue
+		}
+		for _, other := range s.backends {
+			if other != b {
+				_ = other.Delete(key)
+			}
+		}
+		return b, nil
+	}
+	return nil, fmt.Errorf("could not save %s: %v", key, errors.Join(errs...))
+}
+
+// Delete removes key from every backend.
+func (s *Store) Delete(key string) error {
+	var errs []error
+	for _, b := range s.backends {
+		if err := b.Delete(key); err != nil {
+			errs = append(errs, fmt.Errorf("%s store: %v", b.Name(), err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("could not delete %s: %v", key, errors.Join(errs...))
+	}
+	return nil
+}
+
+// Entries reports where each credential of profile is stored. A backend
+// that cannot be read reports the credential as missing.
+func (s *Store) Entries(profile string) []Entry {
+	entries := make([]Entry, 0, len(Names))
+	for _, name := range Names {
+		entry := Entry{Name: name}
+		if _, b, err := s.Get(Key(profile, name)); err == nil {
+			entry.Backend, entry.Location = b.Name(), b.Location()
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
@@ -0,0 +1,95 @@
+package credentials
+
+import (
+	"errors"
+	"testing"
+
+	"inkdown-cli/config"
+)
+
+// memoryBackend is a Backend that keeps secrets in a map, or fails every
+// write when broken.
+type memoryBackend struct {
+	name    string
+	secrets map[string]string
+	broken  bool
+}
+
+func newMemoryBackend(name string) *memoryBackend {
+	return &memoryBackend{name: name, secrets: map[string]string{}}
+}
+
+func (m *memoryBackend) Name() string     { return m.name }
+func (m *memoryBackend) Location() string { return "memory" }
+
+func (m *memoryBackend) Get(key string) (string, error) {
+	secret, ok := m.secrets[key]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return secret, nil
+}
+
+func (m *memoryBackend) Set(key, secret string) error {
+	if m.broken {
+		return errors.New("unavailable")
+	}
+	m.secrets[key] = secret
+	return nil
+}
+
+func (m *memoryBackend) Delete(key string) error {
+	delete(m.secrets, key)
+	return nil
+}
+
+func TestStoreSetMovesToPreferredBackend(t *testing.T) {
+	keyring, file := newMemoryBackend("keyring"), newMemoryBackend("file")
+	file.secrets[InkdownToken] = "old"
+	s := &Store{backends: []Backend{keyring, file}}
+
+	b, err := s.Set(InkdownToken, "new")
+	if err != nil || b != keyring {
+		t.Fatalf("Set = %v, %v, want the keyring backend", b, err)
+	}
+	if _, ok := file.secrets[InkdownToken]; ok {
+		t.Error("Set left a copy in the file backend")
+	}
+
+	secret, b, err := s.Get(InkdownToken)
+	if err != nil || secret != "new" || b != keyring {
+		t.Errorf("Get = %q, %v, %v, want \"new\" from the keyring", secret, b, err)
+	}
+}
+
+func TestStoreSetFallsBack(t *testing.T) {
+	keyring, file := newMemoryBackend("keyring"), newMemoryBackend("file")
+	keyring.broken = true
+	s := &Store{backends: []Backend{keyring, file}}
+
+	b, err := s.Set(GitHubToken, "ghp")
+	if err != nil || b != file {
+		t.Fatalf("Set = %v, %v, want the file backend", b, err)
+	}
+
+	file.broken = true
+	if _, err := s.Set(GitHubToken, "ghp2"); err == nil {
+		t.Error("Set succeeded with every backend broken")
+	}
+}
+
+func TestStoreEntries(t *testing.T) {
+	file := newMemoryBackend("file")
+	file.secrets[GitHubToken] = "ghp"
+	file.secrets[Key("work", InkdownToken)] = "ink"
+	s := &Store{backends: []Backend{file}}
+
+	entries := s.Entries(config.DefaultProfile)
+	if entries[0].Backend != "" || entries[1].Backend != "file" {
+		t.Errorf("default profile entries = %+v", entries)
+	}
+	entries = s.Entries("work")
+	if entries[0].Backend != "file" || entries[1].Backend != "" {
+		t.Errorf("work profile entries = %+v", entries)
+	}
+}
@@ -0,0 +1,181 @@
+package css
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Color is an sRGB color with channels in [0, 1].
+type Color struct {
+	R, G, B, A float64
+}
+
+var namedColors = map[string]string{
+	"black": "#000000", "white": "#ffffff", "red": "#ff0000", "green": "#008000",
+	"blue": "#0000ff", "yellow": "#ffff00", "orange": "#ffa500", "purple": "#800080",
+	"gray": "#808080", "grey": "#808080", "silver": "#c0c0c0", "maroon": "#800000",
+	"navy": "#000080", "teal": "#008080", "olive": "#808000", "lime": "#00ff00",
+	"aqua": "#00ffff", "cyan": "#00ffff", "fuchsia": "#ff00ff", "magenta": "#ff00ff",
+	"darkgray": "#a9a9a9", "darkgrey": "#a9a9a9", "lightgray": "#d3d3d3", "lightgrey": "#d3d3d3",
+	"dimgray": "#696969", "dimgrey": "#696969", "whitesmoke": "#f5f5f5", "gainsboro": "#dcdcdc",
+	"transparent": "#00000000",
+}
+
+// ParseColor parses hex, rgb(), rgba(), hsl(), hsla() and common named
+// colors.
+func ParseColor(value string) (Color, error) {
+	v := strings.ToLower(strings.TrimSpace(value))
+	if hex, ok := namedColors[v]; ok {
+		v = hex
+	}
+
+	switch {
+	case strings.HasPrefix(v, "#"):
+		return parseHex(v)
+	case strings.HasPrefix(v, "rgb(") || strings.HasPrefix(v, "rgba("):
+		return parseFunc(v, false)
+	case strings.HasPrefix(v, "hsl(") || strings.HasPrefix(v, "hsla("):
+		return parseFunc(v, true)
+	}
+	return Color{}, fmt.Errorf("%q is not a color", value)
+}
+
+func parseHex(v string) (Color, error) {
+	digits := v[1:]
+	if len(digits) == 3 || len(digits) == 4 {
+		var expanded strings.Builder
+		for _, d := range digits {
+			expanded.WriteRune(d)
+			expanded.WriteRune(d)
+		}
+		digits = expanded.String()
+	}
+	if len(digits) == 6 {
+		digits += "ff"
+	}
+	if len(digits) != 8 {
+		return Color{}, fmt.Errorf("%q is not a color", v)
+	}
+
+	n, err := strconv.ParseUint(digits, 16, 32)
+	if err != nil {
+		return Color{}, fmt.Errorf("%q is not a color", v)
+	}
+	return Color{
+		R: float64(n>>24&0xff) / 255,
+		G: float64(n>>16&0xff) / 255,
+		B: float64(n>>8&0xff) / 255,
+		A: float64(n&0xff) / 255,
+	}, nil
+}
+
+// parseFunc parses the comma or space separated arguments of rgb() and hsl().
+func parseFunc(v string, hsl bool) (Color, error) {
+	open, close := strings.IndexByte(v, '('), strings.LastIndexByte(v, ')')
+	if close < open {
+		return Color{}, fmt.Errorf("%q is not a color", v)
+	}
+	args := strings.FieldsFunc(v[open+1:close], func(r rune) bool {
+		return r == ',' || r == '/' || r == ' '
+	})
+	if len(args) != 3 && len(args) != 4 {
+		return Color{}, fmt.Errorf("%q is not a color", v)
+	}
+
+	num := func(s string, scale float64) (float64, error) {
+		if p, ok := strings.CutSuffix(s, "%"); ok {
+			f, err := strconv.ParseFloat(p, 64)
+			return f / 100, err
+		}
+		s = strings.TrimSuffix(s, "deg")
+		f, err := strconv.ParseFloat(s, 64)
+		return f / scale, err
+	}
+
+	var c [4]float64
+	c[3] = 1
+	scales := [4]float64{255, 255, 255, 1}
+	if hsl {
+		scales = [4]float64{360, 100, 100, 1}
+	}
+	for i, arg := range args {
+		f, err := num(arg, scales[i])
+		if err != nil {
+			return Color{}, fmt.Errorf("%q is not a color", v)
+		}
+		c[i] = f
+	}
+
+	if hsl {
+		r, g, b := hslToRGB(c[0], c[1], c[2])
+		return Color{R: r, G: g, B: b, A: clamp(c[3])}, nil
+	}
+	return Color{R: clamp(c[0]), G: clamp(c[1]), B: clamp(c[2]), A: clamp(c[3])}, nil
+}
+
+// hslToRGB converts a hue in turns and saturation and lightness in [0, 1].
+func hslToRGB(h, s, l float64) (float64, float64, float64) {
+	h = h - math.Floor(h)
+	s, l = clamp(s), clamp(l)
+	f := func(n float64) float64 {
+		k := math.Mod(n+h*12, 12)
+		a := s * math.Min(l, 1-l)
+		return l - a*math.Max(-1, math.Min(math.Min(k-3, 9-k), 1))
+	}
+	return f(0), f(8), f(4)
+}
+
+func clamp(f float64) float64 {
+	return math.Max(0, math.Min(1, f))
+}
+
+// Over composites c on top of an opaque background.
+func (c Color) Over(bg Color) Color {
+	return Color{
+		R: c.R*c.A + bg.R*(1-c.A),
+		G: c.G*c.A + bg.G*(1-c.A),
+		B: c.B*c.A + bg.B*(1-c.A),
+		A: 1,
+	}
+}
+
+// Mix moves c towards target by t in [0, 1].
+func (c Color) Mix(target Color, t float64) Color {
+	return Color{
+		R: c.R + (target.R-c.R)*t,
+		G: c.G + (target.G-c.G)*t,
+		B: c.B + (target.B-c.B)*t,
+		A: c.A,
+	}
+}
+
+// Hex formats c as #rrggbb, or #rrggbbaa when it is translucent.
+func (c Color) Hex() string {
+	b := func(f float64) int { return int(math.Round(clamp(f) * 255)) }
+	if b(c.A) < 255 {
+		return fmt.Sprintf("#%02x%02x%02x%02x", b(c.R), b(c.G), b(c.B), b(c.A))
+	}
+	return fmt.Sprintf("#%02x%02x%02x", b(c.R), b(c.G), b(c.B))
+}
+
+// Luminance is the WCAG 2.1 relative luminance of an opaque color.
+func (c Color) Luminance() float64 {
+	linear := func(f float64) float64 {
+		if f <= 0.04045 {
+			return f / 12.92
+		}
+		return math.Pow((f+0.055)/1.055, 2.4)
+	}
+	return 0.2126*linear(c.R) + 0.7152*linear(c.G) + 0.0722*linear(c.B)
+}
+
+// Contrast is the WCAG 2.1 contrast ratio of two opaque colors, from 1 to 21.
+func Contrast(a, b Color) float64 {
+	la, lb := a.Luminance(), b.Luminance()
+	if la < lb {
+		la, lb = lb, la
+	}
+	return (la + 0.05) / (lb + 0.05)
+}
@@ -0,0 +1,89 @@
+package css
+
+import (
+	"math"
+	"testing"
+)
+
+func mustColor(t *testing.T, value string) Color {
+	t.Helper()
+	c, err := ParseColor(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestParseColor(t *testing.T) {
+	tests := []struct {
+		value string
+		hex   string
+	}{
+		{"#abc", "#aabbcc"},
+		{"#ABCDEF", "#abcdef"},
+		{"#0008", "#00000088"},
+		{"#11223344", "#11223344"},
+		{"white", "#ffffff"},
+		{" Grey ", "#808080"},
+		{"transparent", "#00000000"},
+		{"rgb(255, 0, 128)", "#ff0080"},
+		{"rgb(100% 50% 0%)", "#ff8000"},
+		{"rgba(0, 0, 0, 0.5)", "#00000080"},
+		{"rgb(0 0 0 / 50%)", "#00000080"},
+		{"rgb(300, -5, 0)", "#ff0000"},
+		{"hsl(120, 100%, 25%)", "#008000"},
+		{"hsl(240deg 100% 50%)", "#0000ff"},
+		{"hsla(0, 0%, 100%, 0.2)", "#ffffff33"},
+	}
+
+	for _, tt := range tests {
+		c, err := ParseColor(tt.value)
+		if err != nil {
+			t.Errorf("ParseColor(%q) error: %v", tt.value, err)
+			continue
+		}
+		if got := c.Hex(); got != tt.hex {
+			t.Errorf("ParseColor(%q) = %s, want %s", tt.value, got, tt.hex)
+		}
+	}
+}
+
+func TestParseColorErrors(t *testing.T) {
+	for _, value := range []string{"", "#12", "#12345", "#ggg", "rgb(1, 2)", "rgb(a, b, c)", "hsl)(", "var(--x)", "blurple"} {
+		if c, err := ParseColor(value); err == nil {
+			t.Errorf("ParseColor(%q) = %s, want an error", value, c.Hex())
+		}
+	}
+}
+
+func TestOver(t *testing.T) {
+	half, _ := ParseColor("rgba(0, 0, 0, 0.5)")
+	white, _ := ParseColor("#fff")
+
+	if got := half.Over(white).Hex(); got != "#808080" {
+		t.Errorf("50%% black over white = %s, want #808080", got)
+	}
+	if got := white.Over(half); got.A != 1 || got.Hex() != "#ffffff" {
+		t.Errorf("opaque white over anything = %s, want #ffffff", got.Hex())
+	}
+}
+
+func TestContrast(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{"#000", "#fff", 21},
+		{"#fff", "#fff", 1},
+		{"#777", "#fff", 4.48},
+		{"rgb(0, 0, 255)", "white", 8.59},
+		{"hsl(0, 100%, 50%)", "#000", 5.25},
+	}
+
+	for _, tt := range tests {
+		got := Contrast(mustColor(t, tt.a), mustColor(t, tt.b))
+		if math.Abs(got-tt.want) > 0.01 {
+			t.Errorf("Contrast(%s, %s) = %.2f, want %.2f", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
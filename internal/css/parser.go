@@ -0,0 +1,328 @@
+// Package css parses stylesheets into rules and declarations with source
+// positions. It is permissive about values and selectors and only rejects
+// input whose structure cannot be recovered.
+package css
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Pos is a 1-based line and rune column.
+type Pos struct {
+	Line int
+	Col  int
+}
+
+// SyntaxError reports malformed CSS.
+type SyntaxError struct {
+	Pos
+	Msg string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("%d:%d: %s", e.Line, e.Col, e.Msg)
+}
+
+// Rule is a qualified rule (AtRule empty) or an at-rule. Selector holds the
+// prelude of a qualified rule and Prelude that of an at-rule.
+type Rule struct {
+	Pos
+	AtRule       string
+	Prelude      string
+	Selector     string
+	Declarations []Declaration
+	Rules        []*Rule
+}
+
+type Declaration struct {
+	Pos
+	Property string
+	Value    string
+}
+
+// URL is a resource the stylesheet loads, from url() or @import.
+type URL struct {
+	Pos
+	Value  string
+	Import bool
+}
+
+type Stylesheet struct {
+	Rules []*Rule
+	URLs  []URL
+}
+
+// Walk calls fn for every rule, nested rules included, and whether it is
+// inside @keyframes.
+func (s *Stylesheet) Walk(fn func(r *Rule, inKeyframes bool)) {
+	var walk func(rules []*Rule, inKeyframes bool)
+	walk = func(rules []*Rule, inKeyframes bool) {
+		for _, r := range rules {
+			fn(r, inKeyframes)
+			walk(r.Rules, inKeyframes || strings.HasSuffix(r.AtRule, "keyframes"))
+		}
+	}
+	walk(s.Rules, false)
+}
+
+// At-rules whose block holds rules rather than declarations.
+var groupingRules = map[string]bool{
+	"media": true, "supports": true, "layer": true, "container": true,
+	"document": true, "scope": true, "starting-style": true,
+	"keyframes": true, "-webkit-keyframes": true, "-moz-keyframes": true,
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+	sheet  *Stylesheet
+}
+
+// Parse parses src and stops at the first syntax error.
+func Parse(src string) (*Stylesheet, error) {
+	tokens, err := tokenize(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{sheet: &Stylesheet{}}
+	for _, t := range tokens {
+		if t.kind != tokComment {
+			p.tokens = append(p.tokens, t)
+		}
+	}
+
+	rules, err := p.parseRules(true)
+	if err != nil {
+		return nil, err
+	}
+	p.sheet.Rules = rules
+	return p.sheet, nil
+}
+
+func (p *parser) peek() token {
+	if p.pos >= len(p.tokens) {
+		return token{kind: tokEOF}
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *parser) skipSpace() {
+	for p.peek().kind == tokSpace {
+		p.pos++
+	}
+}
+
+func (p *parser) lastPos() Pos {
+	if len(p.tokens) == 0 {
+		return Pos{Line: 1, Col: 1}
+	}
+	return p.tokens[len(p.tokens)-1].Pos
+}
+
+// parseRules reads rules until the end of input (top level) or a closing
+// brace, which it consumes.
+func (p *parser) parseRules(top bool) ([]*Rule, error) {
+	var rules []*Rule
+	for {
+		p.skipSpace()
+		t := p.peek()
+
+		switch {
+		case t.kind == tokEOF:
+			if !top {
+				return nil, &SyntaxError{Pos: p.lastPos(), Msg: "unexpected end of file, missing '}'"}
+			}
+			return rules, nil
+		case t.is("}"):
+			if top {
+				return nil, &SyntaxError{Pos: t.Pos, Msg: "unexpected '}'"}
+			}
+			p.pos++
+			return rules, nil
+		case t.is(";"):
+			p.pos++
+		case t.kind == tokAtKeyword:
+			rule, err := p.parseAtRule()
+			if err != nil {
+				return nil, err
+			}
+			rules = append(rules, rule)
+		default:
+			rule, err := p.parseQualifiedRule()
+			if err != nil {
+				return nil, err
+			}
+			rules = append(rules, rule)
+		}
+	}
+}
+
+// prelude collects the text up to a "{" or ";" at depth zero, which is left
+// unconsumed, and records the url() tokens it reads unless it belongs to an
+// @import.
+func (p *parser) prelude(inImport bool) (string, error) {
+	var sb strings.Builder
+	depth := 0
+	for {
+		t := p.peek()
+		switch {
+		case t.kind == tokEOF:
+			return sb.String(), nil
+		case depth == 0 && (t.is("{") || t.is(";") || t.is("}")):
+			return strings.TrimSpace(sb.String()), nil
+		case t.is("(") || t.is("["):
+			depth++
+		case t.is(")") || t.is("]"):
+			if depth > 0 {
+				depth--
+			}
+		}
+		if t.kind == tokURL && !inImport {
+			p.sheet.URLs = append(p.sheet.URLs, URL{Pos: t.Pos, Value: t.value})
+		}
+		if t.kind == tokSpace {
+			sb.WriteByte(' ')
+		} else {
+			sb.WriteString(t.raw)
+		}
+		p.pos++
+	}
+}
+
+func (p *parser) parseAtRule() (*Rule, error) {
+	at := p.peek()
+	p.pos++
+	rule := &Rule{Pos: at.Pos, AtRule: strings.ToLower(at.value)}
+
+	prelude, err := p.prelude(rule.AtRule == "import")
+	if err != nil {
+		return nil, err
+	}
+	rule.Prelude = prelude
+
+	t := p.peek()
+	switch {
+	case t.is(";"):
+		p.pos++
+	case t.is("{"):
+		p.pos++
+		if groupingRules[rule.AtRule] {
+			rule.Rules, err = p.parseRules(false)
+		} else {
+			err = p.parseBlock(rule)
+		}
+		if err != nil {
+			return nil, err
+		}
+	case t.is("}"):
+		// An at-rule without ";" right before the end of a block.
+	default:
+		return nil, &SyntaxError{Pos: at.Pos, Msg: fmt.Sprintf("unterminated @%s rule", at.value)}
+	}
+
+	if rule.AtRule == "import" {
+		p.sheet.URLs = append(p.sheet.URLs, importURL(rule))
+	}
+	return rule, nil
+}
+
+// importURL extracts the location of an @import, whose prelude is either a
+// string or url().
+func importURL(rule *Rule) URL {
+	prelude := rule.Prelude
+	value := prelude
+	if strings.HasPrefix(prelude, "\"") || strings.HasPrefix(prelude, "'") {
+		if end := strings.IndexByte(prelude[1:], prelude[0]); end >= 0 {
+			value = prelude[1 : end+1]
+		}
+	} else if strings.HasPrefix(strings.ToLower(prelude), "url(") {
+		if end := strings.IndexByte(prelude, ')'); end >= 0 {
+			value = strings.Trim(strings.TrimSpace(prelude[4:end]), "\"'")
+		}
+	}
+	return URL{Pos: rule.Pos, Value: value, Import: true}
+}
+
+func (p *parser) parseQualifiedRule() (*Rule, error) {
+	start := p.peek()
+	selector, err := p.prelude(false)
+	if err != nil {
+		return nil, err
+	}
+
+	t := p.peek()
+	if !t.is("{") {
+		if t.kind == tokEOF {
+			return nil, &SyntaxError{Pos: start.Pos, Msg: fmt.Sprintf("expected '{' after selector %q", selector)}
+		}
+		return nil, &SyntaxError{Pos: t.Pos, Msg: fmt.Sprintf("expected '{' after selector %q, found '%s'", selector, t.raw)}
+	}
+	p.pos++
+
+	rule := &Rule{Pos: start.Pos, Selector: selector}
+	if err := p.parseBlock(rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// parseBlock reads declarations and nested rules up to and including the
+// closing brace.
+func (p *parser) parseBlock(rule *Rule) error {
+	for {
+		p.skipSpace()
+		t := p.peek()
+
+		switch {
+		case t.kind == tokEOF:
+			return &SyntaxError{Pos: rule.Pos, Msg: "unclosed block, missing '}'"}
+		case t.is("}"):
+			p.pos++
+			return nil
+		case t.is(";"):
+			p.pos++
+			continue
+		case t.kind == tokAtKeyword:
+			nested, err := p.parseAtRule()
+			if err != nil {
+				return err
+			}
+			rule.Rules = append(rule.Rules, nested)
+			continue
+		}
+
+		start, urls := p.pos, len(p.sheet.URLs)
+		text, err := p.prelude(false)
+		if err != nil {
+			return err
+		}
+
+		if p.peek().is("{") {
+			// A nested rule, as allowed by CSS nesting.
+			p.pos, p.sheet.URLs = start, p.sheet.URLs[:urls]
+			nested, err := p.parseQualifiedRule()
+			if err != nil {
+				return err
+			}
+			rule.Rules = append(rule.Rules, nested)
+			continue
+		}
+
+		colon := strings.IndexByte(text, ':')
+		if colon <= 0 {
+			return &SyntaxError{Pos: t.Pos, Msg: fmt.Sprintf("expected 'property: value', found %q", text)}
+		}
+		property := strings.TrimSpace(text[:colon])
+		if strings.ContainsAny(property, " \t") {
+			return &SyntaxError{Pos: t.Pos, Msg: fmt.Sprintf("invalid property name %q", property)}
+		}
+
+		rule.Declarations = append(rule.Declarations, Declaration{
+			Pos:      t.Pos,
+			Property: property,
+			Value:    strings.TrimSpace(text[colon+1:]),
+		})
+	}
+}
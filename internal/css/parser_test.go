@@ -0,0 +1,119 @@
+package css
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tokens, err := tokenize("a{b:url( x.png );/* c */\n@media\"s\\\"q\"}")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, tok := range tokens {
+		got = append(got, fmt.Sprintf("%d %q %q %d:%d", tok.kind, tok.raw, tok.value, tok.Line, tok.Col))
+	}
+	want := []string{
+		fmt.Sprintf("%d %q %q 1:1", tokWord, "a", ""),
+		fmt.Sprintf("%d %q %q 1:2", tokDelim, "{", ""),
+		fmt.Sprintf("%d %q %q 1:3", tokWord, "b", ""),
+		fmt.Sprintf("%d %q %q 1:4", tokDelim, ":", ""),
+		fmt.Sprintf("%d %q %q 1:5", tokURL, "url( x.png )", "x.png"),
+		fmt.Sprintf("%d %q %q 1:17", tokDelim, ";", ""),
+		fmt.Sprintf("%d %q %q 1:18", tokComment, "/* c */", ""),
+		fmt.Sprintf("%d %q %q 1:25", tokSpace, "\n", ""),
+		fmt.Sprintf("%d %q %q 2:1", tokAtKeyword, "@media", "media"),
+		fmt.Sprintf("%d %q %q 2:7", tokString, `"s\"q"`, `s"q`),
+		fmt.Sprintf("%d %q %q 2:13", tokDelim, "}", ""),
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("tokens:\n got %q\nwant %q", got, want)
+	}
+}
+
+func TestParse(t *testing.T) {
+	sheet, err := Parse(`@import "base.css";
+/* light mode */
+.theme-light {
+  --text-normal: #222;
+  background: url("bg.png") no-repeat;
+  & a:hover { color: var(--link-color) }
+}
+@media (max-width: 600px) {
+  body { font: 12px/1.5 "Inter", sans-serif }
+}
+@keyframes fade { from { opacity: 0 } }
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	sheet.Walk(func(r *Rule, inKeyframes bool) {
+		name := r.Selector
+		if r.AtRule != "" {
+			name = "@" + r.AtRule + " " + r.Prelude
+		}
+		got = append(got, fmt.Sprintf("%d:%d %s keyframes=%v", r.Line, r.Col, name, inKeyframes))
+		for _, d := range r.Declarations {
+			got = append(got, fmt.Sprintf("  %d:%d %s=%s", d.Line, d.Col, d.Property, d.Value))
+		}
+	})
+	want := []string{
+		`1:1 @import "base.css" keyframes=false`,
+		"3:1 .theme-light keyframes=false",
+		"  4:3 --text-normal=#222",
+		`  5:3 background=url("bg.png") no-repeat`,
+		"6:3 & a:hover keyframes=false",
+		"  6:15 color=var(--link-color)",
+		"8:1 @media (max-width: 600px) keyframes=false",
+		"9:3 body keyframes=false",
+		`  9:10 font=12px/1.5 "Inter", sans-serif`,
+		"11:1 @keyframes fade keyframes=false",
+		"11:19 from keyframes=true",
+		"  11:26 opacity=0",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("rules:\n got %q\nwant %q", got, want)
+	}
+
+	wantURLs := []URL{
+		{Pos{1, 1}, "base.css", true},
+		{Pos{5, 15}, "bg.png", false},
+	}
+	if !slices.Equal(sheet.URLs, wantURLs) {
+		t.Errorf("URLs = %v, want %v", sheet.URLs, wantURLs)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		src       string
+		line, col int
+	}{
+		{"a { color: red", 1, 1},
+		{"a { color: red }\n}", 2, 1},
+		{"a { color: 'red }", 1, 12},
+		{"/* open", 1, 1},
+		{"a { b: url(x.png }", 1, 8},
+		{"a {\n  color red;\n}", 2, 3},
+		{"a color: red", 1, 1},
+		{"@media screen", 1, 1},
+	}
+
+	for _, tt := range tests {
+		_, err := Parse(tt.src)
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Errorf("Parse(%q) error = %v, want a SyntaxError", tt.src, err)
+			continue
+		}
+		if syntaxErr.Line != tt.line || syntaxErr.Col != tt.col {
+			t.Errorf("Parse(%q) error at %d:%d, want %d:%d (%v)", tt.src, syntaxErr.Line, syntaxErr.Col, tt.line, tt.col, err)
+		}
+	}
+}
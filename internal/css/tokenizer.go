@@ -0,0 +1,209 @@
+package css
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokSpace
+	tokComment
+	tokWord
+	tokAtKeyword
+	tokString
+	tokURL
+	tokDelim
+)
+
+// token keeps its raw source text so preludes can be rebuilt verbatim.
+// value is the at-rule name, the string contents or the URL.
+type token struct {
+	Pos
+	kind  tokenKind
+	raw   string
+	value string
+}
+
+func (t token) is(delim string) bool {
+	return t.kind == tokDelim && t.raw == delim
+}
+
+const delims = "{}();:[],"
+
+type tokenizer struct {
+	src  string
+	pos  int
+	line int
+	col  int
+}
+
+func tokenize(src string) ([]token, error) {
+	z := &tokenizer{src: src, line: 1, col: 1}
+
+	var tokens []token
+	for z.pos < len(z.src) {
+		t, err := z.next()
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, t)
+	}
+	return tokens, nil
+}
+
+func (z *tokenizer) advance() rune {
+	r, size := utf8.DecodeRuneInString(z.src[z.pos:])
+	z.pos += size
+	if r == '\n' {
+		z.line++
+		z.col = 1
+	} else {
+		z.col++
+	}
+	return r
+}
+
+func (z *tokenizer) peek() byte {
+	if z.pos >= len(z.src) {
+		return 0
+	}
+	return z.src[z.pos]
+}
+
+func (z *tokenizer) next() (token, error) {
+	start, pos := z.pos, Pos{Line: z.line, Col: z.col}
+	c := z.src[z.pos]
+	t := token{Pos: pos}
+
+	switch {
+	case isSpace(c):
+		for z.pos < len(z.src) && isSpace(z.peek()) {
+			z.advance()
+		}
+		t.kind = tokSpace
+
+	case strings.HasPrefix(z.src[z.pos:], "/*"):
+		end := strings.Index(z.src[z.pos+2:], "*/")
+		if end < 0 {
+			return t, &SyntaxError{Pos: pos, Msg: "unterminated comment"}
+		}
+		for z.pos < start+2+end+2 {
+			z.advance()
+		}
+		t.kind = tokComment
+
+	case c == '"' || c == '\'':
+		value, err := z.string()
+		if err != nil {
+			return t, err
+		}
+		t.kind, t.value = tokString, value
+
+	case strings.IndexByte(delims, c) >= 0:
+		z.advance()
+		t.kind = tokDelim
+
+	case c == '@':
+		z.advance()
+		z.word()
+		t.kind, t.value = tokAtKeyword, z.src[start+1:z.pos]
+
+	default:
+		z.word()
+		t.kind = tokWord
+		if strings.EqualFold(z.src[start:z.pos], "url") && z.peek() == '(' {
+			value, err := z.url(pos)
+			if err != nil {
+				return t, err
+			}
+			t.kind, t.value = tokURL, value
+		}
+	}
+
+	t.raw = z.src[start:z.pos]
+	return t, nil
+}
+
+func (z *tokenizer) word() {
+	for z.pos < len(z.src) {
+		c := z.peek()
+		if isSpace(c) || strings.IndexByte(delims, c) >= 0 || c == '"' || c == '\'' ||
+			strings.HasPrefix(z.src[z.pos:], "/*") {
+			return
+		}
+		if c == '\\' {
+			z.advance()
+			if z.pos >= len(z.src) {
+				return
+			}
+		}
+		z.advance()
+	}
+}
+
+func (z *tokenizer) string() (string, error) {
+	pos := Pos{Line: z.line, Col: z.col}
+	quote := z.advance()
+
+	var sb strings.Builder
+	for z.pos < len(z.src) {
+		r := z.advance()
+		switch r {
+		case quote:
+			return sb.String(), nil
+		case '\n':
+			return "", &SyntaxError{Pos: pos, Msg: "unterminated string"}
+		case '\\':
+			if z.pos < len(z.src) {
+				if next := z.advance(); next != '\n' {
+					sb.WriteRune(next)
+				}
+			}
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	return "", &SyntaxError{Pos: pos, Msg: "unterminated string"}
+}
+
+// url reads the argument of url(), quoted or not, and the closing paren.
+func (z *tokenizer) url(pos Pos) (string, error) {
+	z.advance()
+	for z.pos < len(z.src) && isSpace(z.peek()) {
+		z.advance()
+	}
+
+	var value string
+	if c := z.peek(); c == '"' || c == '\'' {
+		s, err := z.string()
+		if err != nil {
+			return "", err
+		}
+		value = s
+		for z.pos < len(z.src) && isSpace(z.peek()) {
+			z.advance()
+		}
+		if z.peek() != ')' {
+			return "", &SyntaxError{Pos: pos, Msg: "unterminated url()"}
+		}
+	} else {
+		end := strings.IndexByte(z.src[z.pos:], ')')
+		if end < 0 || strings.IndexByte(z.src[z.pos:z.pos+end], '\n') >= 0 {
+			return "", &SyntaxError{Pos: pos, Msg: "unterminated url()"}
+		}
+		value = strings.TrimSpace(z.src[z.pos : z.pos+end])
+		for z.peek() != ')' {
+			z.advance()
+		}
+	}
+
+	z.advance()
+	return value, nil
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
@@ -0,0 +1,181 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultContext is the number of unchanged lines shown around each change,
+// matching `diff -u`.
+const DefaultContext = 3
+
+type opKind int
+
+const (
+	opEqual opKind = iota
+	opDelete
+	opInsert
+)
+
+type op struct {
+	kind opKind
+	line string
+	// 0-based indexes into a and b of the line (or of the position it
+	// would occupy for inserts/deletes).
+	ai, bi int
+}
+
+// Unified returns a unified diff of a and b, labelled with oldName and
+// newName. It returns an empty string when the inputs are identical.
+func Unified(oldName, newName, a, b string, context int) string {
+	if a == b {
+		return ""
+	}
+
+	aLines := splitLines(a)
+	bLines := splitLines(b)
+	ops := compute(aLines, bLines)
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n", oldName)
+	fmt.Fprintf(&sb, "+++ %s\n", newName)
+
+	for _, h := range hunks(ops, context) {
+		writeHunk(&sb, ops[h[0]:h[1]])
+	}
+
+	return sb.String()
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.Split(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// compute returns the edit script turning a into b using a longest common
+// subsequence table. Registry files are small, so the quadratic table is fine.
+func compute(a, b []string) []op {
+	n, m := len(a), len(b)
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var ops []op
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, op{opEqual, a[i], i, j})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, op{opDelete, a[i], i, j})
+			i++
+		default:
+			ops = append(ops, op{opInsert, b[j], i, j})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		ops = append(ops, op{opDelete, a[i], i, j})
+	}
+	for ; j < m; j++ {
+		ops = append(ops, op{opInsert, b[j], i, j})
+	}
+
+	return ops
+}
+
+// hunks groups changed ops with their surrounding context and returns the
+// [start, end) op ranges of each hunk.
+func hunks(ops []op, context int) [][2]int {
+	var result [][2]int
+	for i := 0; i < len(ops); i++ {
+		if ops[i].kind == opEqual {
+			continue
+		}
+
+		start := max(i-context, 0)
+		end := i
+		for end < len(ops) {
+			if ops[end].kind != opEqual {
+				end++
+				continue
+			}
+			// Count the run of equal lines; split the hunk only if it is
+			// longer than twice the context.
+			run := end
+			for run < len(ops) && ops[run].kind == opEqual {
+				run++
+			}
+			if run == len(ops) || run-end > 2*context {
+				end = min(end+context, len(ops))
+				break
+			}
+			end = run
+		}
+
+		if n := len(result); n > 0 && start <= result[n-1][1] {
+			result[n-1][1] = end
+		} else {
+			result = append(result, [2]int{start, end})
+		}
+		i = end - 1
+	}
+	return result
+}
+
+func writeHunk(sb *strings.Builder, ops []op) {
+	aStart, bStart := ops[0].ai, ops[0].bi
+	aCount, bCount := 0, 0
+	for _, o := range ops {
+		if o.kind != opInsert {
+			aCount++
+		}
+		if o.kind != opDelete {
+			bCount++
+		}
+	}
+
+	fmt.Fprintf(sb, "@@ -%s +%s @@\n", hunkRange(aStart, aCount), hunkRange(bStart, bCount))
+	for _, o := range ops {
+		switch o.kind {
+		case opEqual:
+			sb.WriteString(" ")
+		case opDelete:
+			sb.WriteString("-")
+		case opInsert:
+			sb.WriteString("+")
+		}
+		sb.WriteString(o.line)
+		sb.WriteString("\n")
+	}
+}
+
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
@@ -0,0 +1,107 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// numbered returns the lines "1" to "n".
+func numbered(n int) []string {
+	lines := make([]string, n)
+	for i := range lines {
+		lines[i] = fmt.Sprint(i + 1)
+	}
+	return lines
+}
+
+// join renders lines as newline-terminated text.
+func join(lines []string) string {
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func TestUnified(t *testing.T) {
+	tests := []struct {
+		name    string
+		a, b    string
+		context int
+		want    string
+	}{
+		{"identical", "a\nb\n", "a\nb\n", 3, ""},
+		{
+			name:    "change in the middle",
+			a:       "a\nb\nc\nd\ne\n",
+			b:       "a\nb\nX\nd\ne\n",
+			context: 1,
+			want:    "--- old\n+++ new\n@@ -2,3 +2,3 @@\n b\n-c\n+X\n d\n",
+		},
+		{
+			name:    "insert into empty",
+			a:       "",
+			b:       "a\nb\n",
+			context: 3,
+			want:    "--- old\n+++ new\n@@ -0,0 +1,2 @@\n+a\n+b\n",
+		},
+		{
+			name:    "delete everything",
+			a:       "a\n",
+			b:       "",
+			context: 3,
+			want:    "--- old\n+++ new\n@@ -1 +0,0 @@\n-a\n",
+		},
+		{
+			name:    "append at the end",
+			a:       join(numbered(5)),
+			b:       join(append(numbered(5), "6")),
+			context: 2,
+			want:    "--- old\n+++ new\n@@ -4,2 +4,3 @@\n 4\n 5\n+6\n",
+		},
+		{
+			name:    "distant changes make separate hunks",
+			a:       join(numbered(10)),
+			b:       strings.Replace(strings.Replace(join(numbered(10)), "2\n", "two\n", 1), "9\n", "nine\n", 1),
+			context: 1,
+			want: "--- old\n+++ new\n" +
+				"@@ -1,3 +1,3 @@\n 1\n-2\n+two\n 3\n" +
+				"@@ -8,3 +8,3 @@\n 8\n-9\n+nine\n 10\n",
+		},
+		{
+			name:    "close changes share a hunk",
+			a:       join(numbered(6)),
+			b:       strings.Replace(strings.Replace(join(numbered(6)), "2\n", "two\n", 1), "4\n", "four\n", 1),
+			context: 1,
+			want:    "--- old\n+++ new\n@@ -1,5 +1,5 @@\n 1\n-2\n+two\n 3\n-4\n+four\n 5\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Unified("old", "new", tt.a, tt.b, tt.context)
+			if got != tt.want {
+				t.Errorf("Unified:\ngot:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestComputeIsMinimal(t *testing.T) {
+	a := []string{"a", "b", "c", "a", "b", "b", "a"}
+	b := []string{"c", "b", "a", "b", "a", "c"}
+
+	var equal, rebuilt []string
+	for _, o := range compute(a, b) {
+		if o.kind == opEqual {
+			equal = append(equal, o.line)
+		}
+		if o.kind != opDelete {
+			rebuilt = append(rebuilt, o.line)
+		}
+	}
+	// The longest common subsequence of the classic example has length 4.
+	if len(equal) != 4 {
+		t.Errorf("kept %d common lines %q, want 4", len(equal), equal)
+	}
+	if strings.Join(rebuilt, "") != strings.Join(b, "") {
+		t.Errorf("applying the edit script gives %q, want %q", rebuilt, b)
+	}
+}
@@ -1,8 +1,10 @@
 package generator
 
 import (
+	"encoding/json"
 	"inkdown-cli/internal/templates"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 )
@@ -32,14 +34,16 @@ func CopyPluginTemplate(dir *string, abs *string, name *string, desc *string) er
 			content := string(data)
 
 			if *name != "" {
-				content = strings.ReplaceAll(content, "My plugin", *name)
+				content = strings.ReplaceAll(content, "My plugin", jsonEscape(*name))
 				if entry.Name() == "manifest.json" {
-					content = strings.ReplaceAll(content, "my-plugin", strings.ToLower(strings.ReplaceAll(*name, " ", "-")))
+					id := PluginID(*name)
+					content = strings.ReplaceAll(content, `"my-plugin-id"`, `"`+id+`"`)
+					content = strings.ReplaceAll(content, "my-plugin", id)
 				}
 			}
 
 			if *desc != "" {
-				content = strings.ReplaceAll(content, "A custom plugin made for inkdown", *desc)
+				content = strings.ReplaceAll(content, "A custom plugin made for inkdown", jsonEscape(*desc))
 			}
 
 			data = []byte(content)
@@ -51,3 +55,123 @@ func CopyPluginTemplate(dir *string, abs *string, name *string, desc *string) er
 
 	return nil
 }
+
+// maxPluginIDLength is the longest id the plugin manifest schema accepts.
+const maxPluginIDLength = 64
+
+// PluginID derives a manifest id from a plugin name: lowercase ASCII letters
+// and digits, with every other run of characters turned into one dash. It
+// returns "" when the name has no letters or digits to keep.
+func PluginID(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+
+	id := b.String()
+	if len(id) > maxPluginIDLength {
+		id = strings.TrimRight(id[:maxPluginIDLength], "-")
+	}
+	return id
+}
+
+// CopyThemeTemplate scaffolds the embedded theme template in dir into abs,
+// writing one stylesheet per mode. Modes without a template stylesheet are
+// seeded from light.css.
+func CopyThemeTemplate(dir *string, abs *string, name *string, author *string, modes []string) error {
+	if len(modes) == 0 {
+		modes = []string{"dark", "light"}
+	}
+
+	entries, err := templates.Templates.ReadDir(*dir)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(*abs, os.ModePerm); err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasSuffix(entry.Name(), ".css") {
+			continue
+		}
+
+		data, err := templates.Templates.ReadFile(path.Join(*dir, entry.Name()))
+		if err != nil {
+			return err
+		}
+
+		if filepath.Ext(entry.Name()) == ".png" {
+			if err := os.WriteFile(filepath.Join(*abs, entry.Name()), data, 0644); err != nil {
+				return err
+			}
+			continue
+		}
+
+		content := string(data)
+
+		if entry.Name() == "theme.json" {
+			if *name != "" {
+				content = strings.ReplaceAll(content, "My theme", jsonEscape(*name))
+			}
+			if *author != "" {
+				content = strings.ReplaceAll(content, "Your name goes here", jsonEscape(*author))
+			}
+
+			modeList, err := json.Marshal(modes)
+			if err != nil {
+				return err
+			}
+			content = strings.ReplaceAll(content, `["dark", "light"]`, strings.ReplaceAll(string(modeList), ",", ", "))
+		} else if *name != "" {
+			content = strings.ReplaceAll(content, "My theme", *name)
+		}
+
+		if err := os.WriteFile(filepath.Join(*abs, entry.Name()), []byte(content), 0644); err != nil {
+			return err
+		}
+	}
+
+	for _, mode := range modes {
+		source := mode + ".css"
+		data, err := templates.Templates.ReadFile(path.Join(*dir, source))
+		if err != nil {
+			source = "light.css"
+			data, err = templates.Templates.ReadFile(path.Join(*dir, source))
+			if err != nil {
+				return err
+			}
+		}
+
+		content := string(data)
+		if source != mode+".css" {
+			content = strings.ReplaceAll(content, "Light mode", strings.ToUpper(mode[:1])+mode[1:]+" mode")
+			content = strings.ReplaceAll(content, ".theme-light", ".theme-"+mode)
+		}
+		if *name != "" {
+			content = strings.ReplaceAll(content, "My theme", *name)
+		}
+
+		if err := os.WriteFile(filepath.Join(*abs, mode+".css"), []byte(content), 0644); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// jsonEscape returns s escaped for use inside a JSON string literal.
+func jsonEscape(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b[1 : len(b)-1])
+}
@@ -0,0 +1,107 @@
+package github
+
+import (
+	"io"
+	"net/http"
+	"strings"
+
+	"inkdown-cli/config"
+)
+
+const (
+	DefaultBaseURL   = "https://api.github.com"
+	DefaultUploadURL = "https://uploads.github.com"
+	DefaultWebURL    = "https://github.com"
+	DefaultUserAgent = "community-cli"
+)
+
+// Client talks to the GitHub REST API. Every endpoint is resolved against the
+// configured URLs so the CLI can target GitHub Enterprise Server or a local
+// stand-in (e.g. an httptest server) instead of github.com.
+type Client struct {
+	// BaseURL is the REST API root, e.g. "https://api.github.com" or
+	// "https://ghes.example.com/api/v3".
+	BaseURL string
+	// UploadURL is the release asset upload root, e.g.
+	// "https://uploads.github.com" or "https://ghes.example.com/api/uploads".
+	UploadURL string
+	// WebURL is the web host serving the OAuth endpoints used by the device flow.
+	WebURL string
+
+	HTTPClient *http.Client
+	UserAgent  string
+	Token      string
+}
+
+// NewClient returns a client for github.com authenticated with token.
+// Token may be empty for endpoints that do not require authentication.
+func NewClient(token string) *Client {
+	return &Client{
+		BaseURL:    DefaultBaseURL,
+		UploadURL:  DefaultUploadURL,
+		WebURL:     DefaultWebURL,
+		HTTPClient: http.DefaultClient,
+		UserAgent:  DefaultUserAgent,
+		Token:      token,
+	}
+}
+
+// NewClientFromEnv returns a client for token whose endpoints honour the
+// INK_GITHUB_* overrides in env.
+func NewClientFromEnv(env *config.Env, token string) *Client {
+	c := NewClient(token)
+	if env.GitHubAPIURL != "" {
+		c.BaseURL = env.GitHubAPIURL
+	}
+	if env.GitHubUploadURL != "" {
+		c.UploadURL = env.GitHubUploadURL
+	}
+	if env.GitHubWebURL != "" {
+		c.WebURL = env.GitHubWebURL
+	}
+	return c
+}
+
+// WithToken returns a copy of the client using a different token.
+func (c *Client) WithToken(token string) *Client {
+	clone := *c
+	clone.Token = token
+	return &clone
+}
+
+// newRequest builds an API request. Paths starting with "/" are resolved
+// against BaseURL; anything else is used as an absolute URL.
+func (c *Client) newRequest(method, path string, body io.Reader) (*http.Request, error) {
+	url := path
+	if strings.HasPrefix(path, "/") {
+		url = strings.TrimSuffix(c.BaseURL, "/") + path
+	}
+
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if c.UserAgent != "" {
+		req.Header.Set("User-Agent", c.UserAgent)
+	}
+
+	return req, nil
+}
+
+// webURL resolves an OAuth endpoint path against WebURL.
+func (c *Client) webURL(path string) string {
+	return strings.TrimSuffix(c.WebURL, "/") + path
+}
+
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return httpClient.Do(req)
+}
@@ -15,21 +15,25 @@ type DeviceCodeResponse struct {
 	Interval        int    `json:"interval"`
 }
 
-func RequestDeviceCode(clientID string) (*DeviceCodeResponse, error) {
+func (c *Client) RequestDeviceCode(clientID string) (*DeviceCodeResponse, error) {
 	data := url.Values{}
 	data.Set("client_id", clientID)
 	data.Set("scope", "public_repo")
 
-	req, _ := http.NewRequest(
+	req, err := http.NewRequest(
 		"POST",
-		"https://github.com/login/device/code",
+		c.webURL("/login/device/code"),
 		strings.NewReader(data.Encode()),
 	)
+	if err != nil {
+		return nil, err
+	}
 
 	req.Header.Set("Accept", "application/json")
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("User-Agent", c.UserAgent)
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -5,26 +5,28 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 type Release struct {
 	ID        int    `json:"id"`
 	TagName   string `json:"tag_name"`
 	Name      string `json:"name"`
+	HTMLURL   string `json:"html_url"`
 	UploadURL string `json:"upload_url"` // "https://uploads.github.com/repos/octocat/Hello-World/releases/1/assets{?name,label}"
 }
 
 // GetReleases fetches all releases for a repository
-func GetReleases(token, owner, repo string) ([]Release, error) {
-	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/releases", owner, repo)
-	req, _ := http.NewRequest("GET", url, nil)
-	req.Header.Set("Authorization", "Bearer "+token)
-	req.Header.Set("Accept", "application/vnd.github+json")
+func (c *Client) GetReleases(owner, repo string) ([]Release, error) {
+	req, err := c.newRequest("GET", fmt.Sprintf("/repos/%s/%s/releases", owner, repo), nil)
+	if err != nil {
+		return nil, err
+	}
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -43,13 +45,13 @@ func GetReleases(token, owner, repo string) ([]Release, error) {
 }
 
 // GetReleaseByTag fetches a specific release by tag
-func GetReleaseByTag(token, owner, repo, tag string) (*Release, error) {
-	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/releases/tags/%s", owner, repo, tag)
-	req, _ := http.NewRequest("GET", url, nil)
-	req.Header.Set("Authorization", "Bearer "+token)
-	req.Header.Set("Accept", "application/vnd.github+json")
+func (c *Client) GetReleaseByTag(owner, repo, tag string) (*Release, error) {
+	req, err := c.newRequest("GET", fmt.Sprintf("/repos/%s/%s/releases/tags/%s", owner, repo, tag), nil)
+	if err != nil {
+		return nil, err
+	}
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -71,8 +73,7 @@ func GetReleaseByTag(token, owner, repo, tag string) (*Release, error) {
 }
 
 // CreateRelease creates a new release
-func CreateRelease(token, owner, repo, tag, name, body string) (*Release, error) {
-	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/releases", owner, repo)
+func (c *Client) CreateRelease(owner, repo, tag, name, body string) (*Release, error) {
 	payload := map[string]interface{}{
 		"tag_name": tag,
 		"name":     name,
@@ -81,11 +82,12 @@ func CreateRelease(token, owner, repo, tag, name, body string) (*Release, error)
 	}
 	jsonBody, _ := json.Marshal(payload)
 
-	req, _ := http.NewRequest("POST", url, bytes.NewBuffer(jsonBody))
-	req.Header.Set("Authorization", "Bearer "+token)
-	req.Header.Set("Accept", "application/vnd.github+json")
+	req, err := c.newRequest("POST", fmt.Sprintf("/repos/%s/%s/releases", owner, repo), bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return nil, err
+	}
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -105,13 +107,13 @@ func CreateRelease(token, owner, repo, tag, name, body string) (*Release, error)
 }
 
 // DeleteRelease deletes a release by ID
-func DeleteRelease(token, owner, repo string, id int) error {
-	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/releases/%d", owner, repo, id)
-	req, _ := http.NewRequest("DELETE", url, nil)
-	req.Header.Set("Authorization", "Bearer "+token)
-	req.Header.Set("Accept", "application/vnd.github+json")
+func (c *Client) DeleteRelease(owner, repo string, id int) error {
+	req, err := c.newRequest("DELETE", fmt.Sprintf("/repos/%s/%s/releases/%d", owner, repo, id), nil)
+	if err != nil {
+		return err
+	}
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return err
 	}
@@ -125,13 +127,13 @@ func DeleteRelease(token, owner, repo string, id int) error {
 }
 
 // DeleteTag deletes a tag reference
-func DeleteTag(token, owner, repo, tag string) error {
-	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/git/refs/tags/%s", owner, repo, tag)
-	req, _ := http.NewRequest("DELETE", url, nil)
-	req.Header.Set("Authorization", "Bearer "+token)
-	req.Header.Set("Accept", "application/vnd.github+json")
+func (c *Client) DeleteTag(owner, repo, tag string) error {
+	req, err := c.newRequest("DELETE", fmt.Sprintf("/repos/%s/%s/git/refs/tags/%s", owner, repo, tag), nil)
+	if err != nil {
+		return err
+	}
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return err
 	}
@@ -144,8 +146,10 @@ func DeleteTag(token, owner, repo, tag string) error {
 	return nil
 }
 
-// UploadReleaseAsset uploads a file to a release
-func UploadReleaseAsset(token, uploadUrlTemplate, filePath, contentType string) error {
+// UploadReleaseAsset uploads a file to a release. The upload endpoint is built
+// from the client's UploadURL rather than the release's upload_url template so
+// that it follows the configured host.
+func (c *Client) UploadReleaseAsset(owner, repo string, releaseID int, filePath, contentType string) error {
 	f, err := os.Open(filePath)
 	if err != nil {
 		return err
@@ -153,15 +157,15 @@ func UploadReleaseAsset(token, uploadUrlTemplate, filePath, contentType string)
 	defer f.Close()
 
 	fileName := filepath.Base(filePath)
-	
-	// Upload URL comes as "https://.../assets{?name,label}", we need to remove the template part
-	// and add ?name=filename
-	cleanUrl := uploadUrlTemplate
-	if idx := len(cleanUrl) - 13; idx > 0 && cleanUrl[idx:] == "{?name,label}" {
-         cleanUrl = cleanUrl[:idx]
-    }
-	
-	url := fmt.Sprintf("%s?name=%s", cleanUrl, fileName)
+
+	uploadURL := fmt.Sprintf(
+		"%s/repos/%s/%s/releases/%d/assets?name=%s",
+		strings.TrimSuffix(c.UploadURL, "/"),
+		owner,
+		repo,
+		releaseID,
+		url.QueryEscape(fileName),
+	)
 
 	// GitHub API for uploads requires raw binary body, but with correct content-length and type
 	stat, err := f.Stat()
@@ -169,13 +173,14 @@ func UploadReleaseAsset(token, uploadUrlTemplate, filePath, contentType string)
 		return err
 	}
 
-	req, _ := http.NewRequest("POST", url, f)
-	req.Header.Set("Authorization", "Bearer "+token)
+	req, err := c.newRequest("POST", uploadURL, f)
+	if err != nil {
+		return err
+	}
 	req.Header.Set("Content-Type", contentType)
 	req.ContentLength = stat.Size() // Set the length explicitly on the request struct
-	req.Header.Set("Accept", "application/vnd.github+json")
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return err
 	}
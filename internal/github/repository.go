@@ -6,7 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"net/http"
+	"net/url"
 	"strings"
 
 	"inkdown-cli/utils"
@@ -36,25 +36,25 @@ type PullRequestPayload struct {
 	Body  string `json:"body"`
 }
 
+// ErrRefExists is returned by CreateBranch when the branch already exists.
+var ErrRefExists = errors.New("reference already exists")
+
 const (
 	ORG_NAME  = "inkdown"
 	REPO_NAME = "inkdown-community"
 	BRANCH    = "main"
 )
 
-func ForkRepo(token string) (string, error) {
-	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/forks", ORG_NAME, REPO_NAME)
-
+func (c *Client) ForkRepo() (string, error) {
 	body := map[string]string{}
 	payload, _ := json.Marshal(body)
 
-	req, _ := http.NewRequest("POST", url, bytes.NewBuffer(payload))
-	req.Header.Set("Authorization", "Bearer "+token)
-	req.Header.Set("Accept", "application/vnd.github+json")
-	req.Header.Set("User-Agent", "community-cli")
+	req, err := c.newRequest("POST", fmt.Sprintf("/repos/%s/%s/forks", ORG_NAME, REPO_NAME), bytes.NewBuffer(payload))
+	if err != nil {
+		return "", err
+	}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return "", err
 	}
@@ -76,15 +76,13 @@ func ForkRepo(token string) (string, error) {
 	return fullName, nil // ex: "usuario/community-plugins"
 }
 
-func GetBranchSHA(token string, repo string) (string, error) {
-	url := fmt.Sprintf("https://api.github.com/repos/%s/git/refs/heads/%s", repo, BRANCH)
-
-	req, _ := http.NewRequest("GET", url, nil)
-	req.Header.Set("Authorization", "Bearer "+token)
-	req.Header.Set("Accept", "application/vnd.github+json")
-	req.Header.Set("User-Agent", "community-cli")
+func (c *Client) GetBranchSHA(repo string) (string, error) {
+	req, err := c.newRequest("GET", fmt.Sprintf("/repos/%s/git/refs/heads/%s", repo, BRANCH), nil)
+	if err != nil {
+		return "", err
+	}
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return "", err
 	}
@@ -113,22 +111,19 @@ func GetBranchSHA(token string, repo string) (string, error) {
 	return sha, nil
 }
 
-func CreateBranch(token string, repo string, newBranch string, baseSHA string) error {
-	url := fmt.Sprintf("https://api.github.com/repos/%s/git/refs", repo)
-	utils.Info("Using the url: %s", url)
-
+func (c *Client) CreateBranch(repo string, newBranch string, baseSHA string) error {
 	body := map[string]string{
 		"ref": "refs/heads/" + newBranch,
 		"sha": baseSHA,
 	}
 	jsonBody, _ := json.Marshal(body)
 
-	req, _ := http.NewRequest("POST", url, bytes.NewBuffer(jsonBody))
-	req.Header.Set("Authorization", "Bearer "+token)
-	req.Header.Set("Accept", "application/vnd.github+json")
-	req.Header.Set("User-Agent", "community-cli")
+	req, err := c.newRequest("POST", fmt.Sprintf("/repos/%s/git/refs", repo), bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return err
+	}
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return err
 	}
@@ -136,20 +131,50 @@ func CreateBranch(token string, repo string, newBranch string, baseSHA string) e
 
 	if resp.StatusCode != 201 {
 		b, _ := io.ReadAll(resp.Body)
+		if resp.StatusCode == 422 && strings.Contains(string(b), "Reference already exists") {
+			return ErrRefExists
+		}
 		return fmt.Errorf("erro criando branch: %s", string(b))
 	}
 
 	return nil
 }
 
-func GetFileContent(token string, owner string, branch string, path string) (string, string, error) {
-	url := fmt.Sprintf("https://api.github.com/repos/%s/contents/%s?ref=%s", owner, path, branch)
-	req, _ := http.NewRequest("GET", url, nil)
-	req.Header.Set("Authorization", "Bearer "+token)
-	req.Header.Set("Accept", "application/vnd.github+json")
-	req.Header.Set("User-Agent", "community-cli")
+// ResetBranch force-moves an existing branch of repo to sha, discarding any
+// commits it had on top.
+func (c *Client) ResetBranch(repo string, branch string, sha string) error {
+	body := map[string]interface{}{
+		"sha":   sha,
+		"force": true,
+	}
+	jsonBody, _ := json.Marshal(body)
+
+	req, err := c.newRequest("PATCH", fmt.Sprintf("/repos/%s/git/refs/heads/%s", repo, branch), bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		b, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("erro atualizando branch: %s", string(b))
+	}
+
+	return nil
+}
+
+func (c *Client) GetFileContent(owner string, branch string, path string) (string, string, error) {
+	req, err := c.newRequest("GET", fmt.Sprintf("/repos/%s/contents/%s?ref=%s", owner, path, branch), nil)
+	if err != nil {
+		return "", "", err
+	}
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return "", "", err
 	}
@@ -171,8 +196,7 @@ func GetFileContent(token string, owner string, branch string, path string) (str
 	return decoded, sha, nil
 }
 
-func UpdateFile(token string, owner string, branch string, path string, newContent, sha string, message string) error {
-	url := fmt.Sprintf("https://api.github.com/repos/%s/contents/%s", owner, path)
+func (c *Client) UpdateFile(owner string, branch string, path string, newContent, sha string, message string) error {
 	contentB64 := utils.EncodeBase64(newContent)
 
 	body := map[string]string{
@@ -183,12 +207,12 @@ func UpdateFile(token string, owner string, branch string, path string, newConte
 	}
 	jsonBody, _ := json.Marshal(body)
 
-	req, _ := http.NewRequest("PUT", url, bytes.NewBuffer(jsonBody))
-	req.Header.Set("Authorization", "Bearer "+token)
-	req.Header.Set("Accept", "application/vnd.github+json")
-	req.Header.Set("User-Agent", "community-cli")
+	req, err := c.newRequest("PUT", fmt.Sprintf("/repos/%s/contents/%s", owner, path), bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return err
+	}
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return err
 	}
@@ -201,8 +225,7 @@ func UpdateFile(token string, owner string, branch string, path string, newConte
 	return nil
 }
 
-func CreatePR(token string, headBranch string, title string, body string) (string, error) {
-	url := fmt.Sprintf("https://api.github.com/repos/%s/pulls", ORG_NAME+"/"+REPO_NAME)
+func (c *Client) CreatePR(headBranch string, title string, body string) (string, error) {
 	payload := map[string]string{
 		"title": title,
 		"head":  headBranch,
@@ -211,12 +234,12 @@ func CreatePR(token string, headBranch string, title string, body string) (strin
 	}
 	jsonBody, _ := json.Marshal(payload)
 
-	req, _ := http.NewRequest("POST", url, bytes.NewBuffer(jsonBody))
-	req.Header.Set("Authorization", "Bearer "+token)
-	req.Header.Set("Accept", "application/vnd.github+json")
-	req.Header.Set("User-Agent", "community-cli")
+	req, err := c.newRequest("POST", fmt.Sprintf("/repos/%s/%s/pulls", ORG_NAME, REPO_NAME), bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return "", err
+	}
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := c.do(req)
 
 	if err != nil {
 		return "", err
@@ -238,13 +261,47 @@ func CreatePR(token string, headBranch string, title string, body string) (strin
 	return prResp.HTMLURL, nil
 }
 
-func GetGitHubUsername(token string) (string, error) {
-	req, _ := http.NewRequest("GET", "https://api.github.com/user", nil)
-	req.Header.Set("Authorization", "Bearer "+token)
-	req.Header.Set("Accept", "application/vnd.github+json")
-	req.Header.Set("User-Agent", "community-cli")
+// FindOpenPR returns the open pull request against the community repository
+// whose head is headBranch ("owner:branch"), or nil if there is none.
+func (c *Client) FindOpenPR(headBranch string) (*PullRequestResponse, error) {
+	query := url.Values{}
+	query.Set("head", headBranch)
+	query.Set("state", "open")
 
-	resp, err := http.DefaultClient.Do(req)
+	req, err := c.newRequest("GET", fmt.Sprintf("/repos/%s/%s/pulls?%s", ORG_NAME, REPO_NAME, query.Encode()), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		b, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("erro buscando PRs: %s", string(b))
+	}
+
+	var prs []PullRequestResponse
+	if err := json.NewDecoder(resp.Body).Decode(&prs); err != nil {
+		return nil, err
+	}
+	if len(prs) == 0 {
+		return nil, nil
+	}
+
+	return &prs[0], nil
+}
+
+func (c *Client) GetGitHubUsername() (string, error) {
+	req, err := c.newRequest("GET", "/user", nil)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := c.do(req)
 	if err != nil {
 		return "", err
 	}
@@ -261,15 +318,3 @@ func GetGitHubUsername(token string) (string, error) {
 
 	return user.Login, nil
 }
-
-func AppendPlugin(existing string, pluginJSON string) string {
-	existing = strings.TrimSpace(existing)
-	existing = strings.TrimSuffix(existing, "]")
-
-	if !strings.HasSuffix(existing, "[") {
-		existing += ",\n"
-	}
-
-	existing += pluginJSON + "\n]"
-	return existing
-}
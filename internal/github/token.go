@@ -1,92 +1,213 @@
 package github
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
-	"os"
-	"path/filepath"
 	"strings"
 	"time"
 )
 
 type TokenResponse struct {
-	AccessToken string `json:"access_token"`
-	TokenType   string `json:"token_type"`
-	Scope       string `json:"scope"`
-	Error       string `json:"error"`
+	AccessToken      string `json:"access_token"`
+	TokenType        string `json:"token_type"`
+	Scope            string `json:"scope"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+	// Interval is the new polling interval GitHub sends with slow_down.
+	Interval int `json:"interval"`
 }
 
-func PollForToken(clientID, deviceCode string, interval int) (string, error) {
-	for {
-		time.Sleep(time.Duration(interval) * time.Second)
+// OAuthError is an error response from the device flow token endpoint.
+// Compare with errors.Is against ErrAccessDenied and ErrExpiredToken.
+type OAuthError struct {
+	Code        string
+	Description string
+}
 
-		data := url.Values{}
-		data.Set("client_id", clientID)
-		data.Set("device_code", deviceCode)
-		data.Set("grant_type", "urn:ietf:params:oauth:grant-type:device_code")
+func (e *OAuthError) Error() string {
+	if e.Description != "" {
+		return fmt.Sprintf("oauth error: %s: %s", e.Code, e.Description)
+	}
+	return "oauth error: " + e.Code
+}
 
-		req, _ := http.NewRequest(
-			"POST",
-			"https://github.com/login/oauth/access_token",
-			strings.NewReader(data.Encode()),
-		)
+func (e *OAuthError) Is(target error) bool {
+	t, ok := target.(*OAuthError)
+	return ok && t.Code == e.Code
+}
 
-		req.Header.Set("Accept", "application/json")
-		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+var (
+	// ErrAccessDenied means the user declined the authorization request.
+	ErrAccessDenied = &OAuthError{Code: "access_denied", Description: "the authorization request was denied"}
+	// ErrExpiredToken means the device code expired before it was entered.
+	ErrExpiredToken = &OAuthError{Code: "expired_token", Description: "the device code expired"}
+)
+
+const (
+	// defaultDeviceExpiry and defaultPollInterval apply when GitHub omits
+	// expires_in or interval.
+	defaultDeviceExpiry = 15 * time.Minute
+	defaultPollInterval = 5 * time.Second
+	// slowDownStep is added to the interval on every slow_down (RFC 8628).
+	slowDownStep = 5 * time.Second
+)
+
+// PollForToken waits for the user to enter code and returns the access
+// token. It polls at the interval GitHub asked for, backing off on
+// slow_down, and gives up with ErrExpiredToken once the code expires or with
+// ctx's error when ctx is cancelled. progress, if set, is called every
+// second with the time left before the code expires.
+func (c *Client) PollForToken(ctx context.Context, clientID string, code *DeviceCodeResponse, progress func(remaining time.Duration)) (string, error) {
+	interval := time.Duration(code.Interval) * time.Second
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+	expiry := time.Duration(code.ExpiresIn) * time.Second
+	if expiry <= 0 {
+		expiry = defaultDeviceExpiry
+	}
+	deadline := time.Now().Add(expiry)
 
-		resp, err := http.DefaultClient.Do(req)
+	tick := time.NewTicker(time.Second)
+	defer tick.Stop()
+
+	for {
+		poll := time.NewTimer(interval)
+	wait:
+		for {
+			if progress != nil {
+				progress(time.Until(deadline).Round(time.Second))
+			}
+			select {
+			case <-ctx.Done():
+				poll.Stop()
+				return "", ctx.Err()
+			case <-tick.C:
+				if time.Now().After(deadline) {
+					poll.Stop()
+					return "", ErrExpiredToken
+				}
+			case <-poll.C:
+				break wait
+			}
+		}
+
+		res, err := c.requestToken(ctx, clientID, code.DeviceCode)
 		if err != nil {
 			return "", err
 		}
 
-		var res TokenResponse
-		json.NewDecoder(resp.Body).Decode(&res)
-		resp.Body.Close()
-
-		if res.AccessToken != "" {
+		switch res.Error {
+		case "":
+			if res.AccessToken == "" {
+				return "", errors.New("GitHub returned neither a token nor an error")
+			}
 			return res.AccessToken, nil
-		}
-
-		if res.Error != "" && res.Error != "authorization_pending" {
-			return "", fmt.Errorf("oauth error: %s", res.Error)
+		case "authorization_pending":
+		case "slow_down":
+			interval += slowDownStep
+			if next := time.Duration(res.Interval) * time.Second; next > interval {
+				interval = next
+			}
+		case ErrAccessDenied.Code:
+			return "", ErrAccessDenied
+		case ErrExpiredToken.Code:
+			return "", ErrExpiredToken
+		default:
+			return "", &OAuthError{Code: res.Error, Description: res.ErrorDescription}
 		}
 	}
 }
 
-func ValidateToken(token string) error {
-	req, _ := http.NewRequest("GET", "https://api.github.com/user", nil)
-	req.Header.Set("Authorization", "Bearer "+token)
-	req.Header.Set("User-Agent", "community-cli")
+// requestToken makes one request to the device flow token endpoint.
+func (c *Client) requestToken(ctx context.Context, clientID, deviceCode string) (*TokenResponse, error) {
+	data := url.Values{}
+	data.Set("client_id", clientID)
+	data.Set("device_code", deviceCode)
+	data.Set("grant_type", "urn:ietf:params:oauth:grant-type:device_code")
+
+	req, err := http.NewRequestWithContext(
+		ctx,
+		"POST",
+		c.webURL("/login/oauth/access_token"),
+		strings.NewReader(data.Encode()),
+	)
+	if err != nil {
+		return nil, err
+	}
 
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil || resp.StatusCode != 200 {
-		return errors.New("Invalid token")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("User-Agent", c.UserAgent)
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, err
 	}
-	return nil
+	defer resp.Body.Close()
+
+	var res TokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return nil, fmt.Errorf("invalid response from GitHub (%s): %v", resp.Status, err)
+	}
+	return &res, nil
 }
 
-func SaveToken(token string) error {
-	home, _ := os.UserHomeDir()
-	dir := filepath.Join(home, ".community-cli")
+func (c *Client) ValidateToken() error {
+	req, err := c.newRequest("GET", "/user", nil)
+	if err != nil {
+		return err
+	}
 
-	os.MkdirAll(dir, 0700)
+	resp, err := c.do(req)
+	if err != nil {
+		return errors.New("Invalid token")
+	}
+	defer resp.Body.Close()
 
-	path := filepath.Join(dir, "token")
-	return os.WriteFile(path, []byte(token), 0600)
+	if resp.StatusCode != 200 {
+		return errors.New("Invalid token")
+	}
+	return nil
 }
 
-func LoadToken() (string, error) {
-	home, err := os.UserHomeDir()
+// RevokeGrant revokes the OAuth app's authorization for token, invalidating
+// every token issued under it. GitHub only accepts this with the app's
+// client secret.
+func (c *Client) RevokeGrant(clientID, clientSecret, token string) error {
+	body, err := json.Marshal(map[string]string{"access_token": token})
+	if err != nil {
+		return err
+	}
+
+	req, err := c.WithToken("").newRequest("DELETE", "/applications/"+clientID+"/grant", bytes.NewReader(body))
 	if err != nil {
-		return "", err
+		return err
 	}
-	path := filepath.Join(home, ".community-cli", "token")
-	b, err := os.ReadFile(path)
+	req.SetBasicAuth(clientID, clientSecret)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.do(req)
 	if err != nil {
-		return "", err
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNoContent, http.StatusNotFound:
+		// 404 means the grant is already gone.
+		return nil
 	}
-	return strings.TrimSpace(string(b)), nil
+	return fmt.Errorf("revoking the grant failed: %s", resp.Status)
+}
+
+// GrantSettingsURL is where the user can revoke the app's authorization
+// themselves.
+func (c *Client) GrantSettingsURL(clientID string) string {
+	return c.webURL("/settings/connections/applications/" + clientID)
 }
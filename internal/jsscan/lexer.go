@@ -0,0 +1,628 @@
+package jsscan
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type Kind int
+
+const (
+	Ident Kind = iota
+	Number
+	// String is a quoted string or a template literal without substitutions.
+	// Value holds the decoded contents.
+	String
+	// Template is a chunk of a template literal with substitutions.
+	Template
+	Regex
+	Punct
+	Comment
+	// JSX is a JSX tag or attribute name. Text between JSX tags produces no
+	// tokens, and neither do the angle brackets around tags.
+	JSX
+)
+
+// Token is a lexical token. Line and Col are 1-based; Col counts runes.
+type Token struct {
+	Kind  Kind
+	Value string
+	Line  int
+	Col   int
+}
+
+// SyntaxError reports input the lexer could not tokenize.
+type SyntaxError struct {
+	Line int
+	Col  int
+	Msg  string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("%d:%d: %s", e.Line, e.Col, e.Msg)
+}
+
+// Longest punctuators first so that matching is greedy.
+var punctuators = []string{
+	">>>=",
+	"...", "===", "!==", "**=", "<<=", ">>=", ">>>", "&&=", "||=", "??=",
+	"=>", "==", "!=", "<=", ">=", "&&", "||", "??", "?.", "++", "--",
+	"+=", "-=", "*=", "/=", "%=", "&=", "|=", "^=", "<<", ">>", "**",
+	"{", "}", "(", ")", "[", "]", ";", ",", "<", ">", "+", "-", "*", "/",
+	"%", "&", "|", "^", "!", "~", "?", ":", "=", ".", "@",
+}
+
+// Keywords after which a "/" starts a regular expression, not a division.
+var regexKeywords = map[string]bool{
+	"return": true, "typeof": true, "instanceof": true, "in": true, "of": true,
+	"new": true, "delete": true, "void": true, "throw": true, "case": true,
+	"do": true, "else": true, "yield": true, "await": true,
+}
+
+// braceKind tells what the closing "}" of an open brace resumes.
+type braceKind int
+
+const (
+	braceBlock braceKind = iota
+	// braceTemplate is a template literal "${" substitution.
+	braceTemplate
+	// braceJSXAttrs is a "{" expression or spread in a JSX tag.
+	braceJSXAttrs
+	// braceJSXChildren is a "{" expression between JSX tags.
+	braceJSXChildren
+)
+
+type brace struct {
+	kind braceKind
+	// elems is the number of JSX elements open around a JSX brace.
+	elems int
+}
+
+// jsxMode is where lexJSX is inside a JSX element.
+type jsxMode int
+
+const (
+	jsxTag jsxMode = iota
+	jsxAttrs
+	jsxChildren
+)
+
+type lexer struct {
+	src  string
+	pos  int
+	line int
+	col  int
+	jsx  bool
+
+	tokens []Token
+	// last is the last token that is not a comment.
+	last    Token
+	braces  []brace
+	regexOK bool
+	// elems counts the open JSX elements of the JSX expression being lexed.
+	elems int
+}
+
+// Tokenize splits JavaScript or TypeScript source into tokens, including
+// comments. jsx enables JSX syntax, as in .jsx and .tsx files.
+func Tokenize(src string, jsx bool) ([]Token, error) {
+	l := &lexer{src: src, line: 1, col: 1, jsx: jsx, regexOK: true, last: Token{Kind: -1}}
+	if err := l.run(); err != nil {
+		return l.tokens, err
+	}
+	return l.tokens, nil
+}
+
+func (l *lexer) peek() rune {
+	if l.pos >= len(l.src) {
+		return -1
+	}
+	r, _ := utf8.DecodeRuneInString(l.src[l.pos:])
+	return r
+}
+
+func (l *lexer) peekAt(offset int) byte {
+	if l.pos+offset >= len(l.src) {
+		return 0
+	}
+	return l.src[l.pos+offset]
+}
+
+func (l *lexer) next() rune {
+	if l.pos >= len(l.src) {
+		return -1
+	}
+	r, size := utf8.DecodeRuneInString(l.src[l.pos:])
+	l.pos += size
+	if r == '\n' {
+		l.line++
+		l.col = 1
+	} else {
+		l.col++
+	}
+	return r
+}
+
+func (l *lexer) errorf(line, col int, format string, args ...interface{}) error {
+	return &SyntaxError{Line: line, Col: col, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (l *lexer) emit(kind Kind, value string, line, col int) {
+	t := Token{Kind: kind, Value: value, Line: line, Col: col}
+	l.tokens = append(l.tokens, t)
+	if kind == Comment {
+		// Comments do not change what may follow.
+		return
+	}
+
+	switch kind {
+	case Ident:
+		// Keywords are plain names after a dot, e.g. x.delete / 2.
+		l.regexOK = regexKeywords[value] && !isPunct(l.last, ".", "?.")
+	case Punct:
+		switch value {
+		case ")", "]", "}":
+			l.regexOK = false
+		case "++", "--", "!":
+			// Postfix ++ and --, and the TypeScript non-null assertion, end
+			// an operand: i++ / 2, n! / 2.
+			l.regexOK = !isOperand(l.last)
+		default:
+			l.regexOK = true
+		}
+	default:
+		l.regexOK = false
+	}
+	l.last = t
+}
+
+// isOperand reports whether t can end an operand, so that an operator, not
+// another operand, follows it.
+func isOperand(t Token) bool {
+	switch t.Kind {
+	case Ident:
+		return !regexKeywords[t.Value]
+	case Number, String, Regex:
+		return true
+	case Punct:
+		return t.Value == ")" || t.Value == "]"
+	}
+	return false
+}
+
+func isPunct(t Token, values ...string) bool {
+	if t.Kind != Punct {
+		return false
+	}
+	for _, v := range values {
+		if t.Value == v {
+			return true
+		}
+	}
+	return false
+}
+
+func (l *lexer) run() error {
+	for {
+		r := l.peek()
+		for r != -1 && isSpace(r) {
+			l.next()
+			r = l.peek()
+		}
+		if r == -1 {
+			return nil
+		}
+
+		line, col, start := l.line, l.col, l.pos
+
+		switch {
+		case r == '/' && l.peekAt(1) == '/':
+			for r := l.peek(); r != -1 && r != '\n'; r = l.peek() {
+				l.next()
+			}
+			l.emit(Comment, l.src[start+2:l.pos], line, col)
+
+		case r == '/' && l.peekAt(1) == '*':
+			end := strings.Index(l.src[l.pos+2:], "*/")
+			if end < 0 {
+				return l.errorf(line, col, "unterminated comment")
+			}
+			for l.pos < start+2+end+2 {
+				l.next()
+			}
+			l.emit(Comment, l.src[start+2:l.pos-2], line, col)
+
+		case r == '/' && l.regexOK:
+			if err := l.lexRegex(line, col); err != nil {
+				return err
+			}
+
+		case r == '"' || r == '\'':
+			if err := l.lexString(line, col); err != nil {
+				return err
+			}
+
+		case r == '`':
+			l.next()
+			if err := l.lexTemplate(line, col, true); err != nil {
+				return err
+			}
+
+		case r == '<' && l.jsx && l.regexOK && l.jsxStart():
+			if err := l.lexJSX(jsxTag); err != nil {
+				return err
+			}
+
+		case isDigit(r) || (r == '.' && isDigit(rune(l.peekAt(1)))):
+			l.lexNumber(line, col)
+
+		case isIdentStart(r) || r == '#' || r == '\\':
+			l.next()
+			for r := l.peek(); r != -1 && (isIdentPart(r) || r == '\\'); r = l.peek() {
+				l.next()
+			}
+			l.emit(Ident, l.src[start:l.pos], line, col)
+
+		case r == '}' && len(l.braces) > 0 && l.braces[len(l.braces)-1].kind != braceBlock:
+			b := l.braces[len(l.braces)-1]
+			l.braces = l.braces[:len(l.braces)-1]
+			l.next()
+			if b.kind == braceTemplate {
+				if err := l.lexTemplate(line, col, false); err != nil {
+					return err
+				}
+				continue
+			}
+
+			l.emit(Punct, "}", line, col)
+			l.elems = b.elems
+			mode := jsxChildren
+			if b.kind == braceJSXAttrs {
+				mode = jsxAttrs
+			}
+			if err := l.lexJSX(mode); err != nil {
+				return err
+			}
+
+		default:
+			p := l.matchPunct()
+			if p == "" {
+				return l.errorf(line, col, "unexpected character %q", r)
+			}
+			for range p {
+				l.next()
+			}
+			switch p {
+			case "{":
+				l.braces = append(l.braces, brace{kind: braceBlock})
+			case "}":
+				if len(l.braces) > 0 {
+					l.braces = l.braces[:len(l.braces)-1]
+				}
+			}
+			l.emit(Punct, p, line, col)
+		}
+	}
+}
+
+func (l *lexer) matchPunct() string {
+	rest := l.src[l.pos:]
+	for _, p := range punctuators {
+		if strings.HasPrefix(rest, p) {
+			// "?." followed by a digit is a conditional, e.g. a?.5:b
+			if p == "?." && len(rest) > 2 && isDigit(rune(rest[2])) {
+				continue
+			}
+			return p
+		}
+	}
+	return ""
+}
+
+func (l *lexer) lexNumber(line, col int) {
+	start := l.pos
+	for {
+		r := l.peek()
+		if isIdentPart(r) || r == '.' {
+			l.next()
+			if (r == 'e' || r == 'E') && !strings.HasPrefix(l.src[start:], "0x") && !strings.HasPrefix(l.src[start:], "0X") {
+				if s := l.peek(); s == '+' || s == '-' {
+					l.next()
+				}
+			}
+			continue
+		}
+		break
+	}
+	l.emit(Number, l.src[start:l.pos], line, col)
+}
+
+func (l *lexer) lexString(line, col int) error {
+	quote := l.next()
+	var sb strings.Builder
+	for {
+		r := l.next()
+		switch r {
+		case -1, '\n':
+			return l.errorf(line, col, "unterminated string literal")
+		case quote:
+			l.emit(String, sb.String(), line, col)
+			return nil
+		case '\\':
+			if err := l.lexEscape(&sb, line, col); err != nil {
+				return err
+			}
+		default:
+			sb.WriteRune(r)
+		}
+	}
+}
+
+// lexTemplate reads a template literal chunk after "`" or after the "}"
+// closing a substitution.
+func (l *lexer) lexTemplate(line, col int, head bool) error {
+	var sb strings.Builder
+	for {
+		r := l.next()
+		switch {
+		case r == -1:
+			return l.errorf(line, col, "unterminated template literal")
+		case r == '`':
+			if head {
+				l.emit(String, sb.String(), line, col)
+			} else {
+				l.emit(Template, sb.String(), line, col)
+			}
+			return nil
+		case r == '$' && l.peek() == '{':
+			l.next()
+			l.braces = append(l.braces, brace{kind: braceTemplate})
+			l.emit(Template, sb.String(), line, col)
+			l.regexOK = true
+			return nil
+		case r == '\\':
+			if err := l.lexEscape(&sb, line, col); err != nil {
+				return err
+			}
+		default:
+			sb.WriteRune(r)
+		}
+	}
+}
+
+func (l *lexer) lexEscape(sb *strings.Builder, line, col int) error {
+	r := l.next()
+	switch r {
+	case -1:
+		return l.errorf(line, col, "unterminated string literal")
+	case 'n':
+		sb.WriteByte('\n')
+	case 't':
+		sb.WriteByte('\t')
+	case 'r':
+		sb.WriteByte('\r')
+	case 'b':
+		sb.WriteByte('\b')
+	case 'f':
+		sb.WriteByte('\f')
+	case 'v':
+		sb.WriteByte('\v')
+	case '0':
+		sb.WriteByte(0)
+	case '\n':
+		// Line continuation.
+	case 'x':
+		sb.WriteRune(l.hexRune(2))
+	case 'u':
+		if l.peek() == '{' {
+			l.next()
+			start := l.pos
+			for r := l.peek(); r != -1 && r != '}'; r = l.peek() {
+				l.next()
+			}
+			v, _ := strconv.ParseUint(l.src[start:l.pos], 16, 32)
+			l.next()
+			sb.WriteRune(rune(v))
+		} else {
+			sb.WriteRune(l.hexRune(4))
+		}
+	default:
+		sb.WriteRune(r)
+	}
+	return nil
+}
+
+func (l *lexer) hexRune(digits int) rune {
+	start := l.pos
+	for i := 0; i < digits && isHex(l.peek()); i++ {
+		l.next()
+	}
+	v, err := strconv.ParseUint(l.src[start:l.pos], 16, 32)
+	if err != nil {
+		return utf8.RuneError
+	}
+	return rune(v)
+}
+
+// jsxStart reports whether the "<" at the current position opens a JSX
+// element rather than the type parameters of a generic arrow function, which
+// .tsx files write as <T,>(...) or <T extends U>(...).
+func (l *lexer) jsxStart() bool {
+	rest := l.src[l.pos+1:]
+	r, _ := utf8.DecodeRuneInString(rest)
+	if r == '>' {
+		return true
+	}
+	if !isIdentStart(r) {
+		return false
+	}
+	name := strings.TrimLeftFunc(rest, isIdentPart)
+	after := strings.TrimLeftFunc(name, isSpace)
+	if strings.HasPrefix(after, ",") {
+		return false
+	}
+	if rest, ok := strings.CutPrefix(after, "extends"); ok && after != name {
+		r, _ := utf8.DecodeRuneInString(rest)
+		return !isSpace(r)
+	}
+	return true
+}
+
+// lexJSX reads a JSX element starting at mode, up to the end of the outermost
+// element or to a "{" expression, which run lexes as code. The "}" closing
+// the expression calls lexJSX again to resume the element.
+func (l *lexer) lexJSX(mode jsxMode) error {
+	for {
+		switch mode {
+		case jsxTag:
+			line, col := l.line, l.col
+			l.next()
+			l.skipSpace()
+			closing := l.peek() == '/'
+			if closing {
+				l.next()
+				l.skipSpace()
+			}
+			if nameLine, nameCol, name := l.line, l.col, l.jsxName(); name != "" {
+				l.emit(JSX, name, nameLine, nameCol)
+			}
+			if !closing {
+				l.elems++
+				mode = jsxAttrs
+				continue
+			}
+			l.skipSpace()
+			if l.peek() != '>' {
+				return l.errorf(line, col, "unterminated JSX closing tag")
+			}
+			l.next()
+			l.elems--
+			mode = jsxChildren
+
+		case jsxAttrs:
+			l.skipSpace()
+			line, col := l.line, l.col
+			switch r := l.peek(); {
+			case r == '>':
+				l.next()
+				mode = jsxChildren
+			case r == '/' && l.peekAt(1) == '>':
+				l.next()
+				l.next()
+				l.elems--
+				mode = jsxChildren
+			case r == '{':
+				l.openJSXBrace(braceJSXAttrs, line, col)
+				return nil
+			case r == '"' || r == '\'':
+				quote := l.next()
+				start := l.pos
+				for r := l.peek(); r != quote; r = l.peek() {
+					if r == -1 {
+						return l.errorf(line, col, "unterminated string literal")
+					}
+					l.next()
+				}
+				l.emit(String, l.src[start:l.pos], line, col)
+				l.next()
+			case r == '=':
+				l.next()
+			case isIdentStart(r):
+				l.emit(JSX, l.jsxName(), line, col)
+			case r == -1:
+				return l.errorf(line, col, "unterminated JSX tag")
+			default:
+				return l.errorf(line, col, "unexpected character %q in JSX tag", r)
+			}
+
+		case jsxChildren:
+			if l.elems == 0 {
+				// The element is an operand.
+				l.regexOK = false
+				return nil
+			}
+			for r := l.peek(); r != '{' && r != '<'; r = l.peek() {
+				if r == -1 {
+					return l.errorf(l.line, l.col, "unterminated JSX element")
+				}
+				l.next()
+			}
+			if l.peek() == '{' {
+				l.openJSXBrace(braceJSXChildren, l.line, l.col)
+				return nil
+			}
+			mode = jsxTag
+		}
+	}
+}
+
+func (l *lexer) openJSXBrace(kind braceKind, line, col int) {
+	l.next()
+	l.braces = append(l.braces, brace{kind: kind, elems: l.elems})
+	l.elems = 0
+	l.emit(Punct, "{", line, col)
+}
+
+// jsxName reads a tag or attribute name, which may contain "-", ":" and ".".
+func (l *lexer) jsxName() string {
+	start := l.pos
+	for r := l.peek(); r != -1 && (isIdentPart(r) || r == '-' || r == ':' || r == '.'); r = l.peek() {
+		l.next()
+	}
+	return l.src[start:l.pos]
+}
+
+func (l *lexer) skipSpace() {
+	for r := l.peek(); r != -1 && isSpace(r); r = l.peek() {
+		l.next()
+	}
+}
+
+func (l *lexer) lexRegex(line, col int) error {
+	start := l.pos
+	l.next()
+	inClass := false
+	for {
+		r := l.next()
+		switch {
+		case r == -1 || r == '\n':
+			return l.errorf(line, col, "unterminated regular expression")
+		case r == '\\':
+			l.next()
+		case r == '[':
+			inClass = true
+		case r == ']':
+			inClass = false
+		case r == '/' && !inClass:
+			for isIdentPart(l.peek()) {
+				l.next()
+			}
+			l.emit(Regex, l.src[start:l.pos], line, col)
+			return nil
+		}
+	}
+}
+
+func isSpace(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\n' || r == '\r' || r == '\v' || r == '\f' ||
+		r == 0xa0 || r == 0xfeff || r == 0x2028 || r == 0x2029 || unicode.IsSpace(r)
+}
+
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+func isHex(r rune) bool {
+	return isDigit(r) || (r >= 'a' && r <= 'f') || (r >= 'A' && r <= 'F')
+}
+
+func isIdentStart(r rune) bool {
+	return r == '$' || r == '_' || unicode.IsLetter(r)
+}
+
+func isIdentPart(r rune) bool {
+	return isIdentStart(r) || unicode.IsDigit(r) || r == 0x200c || r == 0x200d
+}
@@ -0,0 +1,169 @@
+package jsscan
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestTokenizeDivisionAndRegex(t *testing.T) {
+	tests := []struct {
+		name  string
+		src   string
+		jsx   bool
+		regex []string
+	}{
+		{"division after identifier", "a / b / c", false, nil},
+		{"division after call", "f(x) / 2", false, nil},
+		{"division after index", "a[0] / 2", false, nil},
+		{"regex at statement start", "/ab+c/.test(s)", false, []string{"/ab+c/"}},
+		{"regex after return", "return /x/g", false, []string{"/x/g"}},
+		{"regex after operator", "s.replace(/a|b/, '')", false, []string{"/a|b/"}},
+		{"division after postfix increment", "const a = i++ / 2;", false, nil},
+		{"division after postfix decrement", "const a = arr[0]-- / 2;", false, nil},
+		{"division after postfix on call result", "(x)++ / 2", false, nil},
+		{"regex after prefix increment operand", "x = ++i; /re/.exec(s)", false, []string{"/re/"}},
+		{"division after non-null assertion", "const h = n! / 2;", false, nil},
+		{"division after non-null call", "f()! / g()!", false, nil},
+		{"regex after logical not", "if (!/x/.test(s)) {}", false, []string{"/x/"}},
+		{"keyword as property name", "x.delete / 2", false, nil},
+		{"regex with class containing slash", "/[/]+/.test(s)", false, []string{"/[/]+/"}},
+		{"regex in template substitution", "`${/x/.source}`", false, []string{"/x/"}},
+		{"division after jsx element", "const a = <b /> / 2", true, nil},
+		{"regex in jsx expression", "<a>{/x/.test(s)}</a>", true, []string{"/x/"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, err := Tokenize(tt.src, tt.jsx)
+			if err != nil {
+				t.Fatalf("Tokenize(%q) error: %v", tt.src, err)
+			}
+			var regex []string
+			for _, tok := range tokens {
+				if tok.Kind == Regex {
+					regex = append(regex, tok.Value)
+				}
+			}
+			if !slices.Equal(regex, tt.regex) {
+				t.Errorf("Tokenize(%q) regexes = %q, want %q", tt.src, regex, tt.regex)
+			}
+		})
+	}
+}
+
+func TestTokenizeStringsAndComments(t *testing.T) {
+	tokens, err := Tokenize("// a\nlet s = 'it\\'s' + \"\\u0041\\x42\" /* b */ + `c`", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Token{
+		{Comment, " a", 1, 1},
+		{Ident, "let", 2, 1},
+		{Ident, "s", 2, 5},
+		{Punct, "=", 2, 7},
+		{String, "it's", 2, 9},
+		{Punct, "+", 2, 17},
+		{String, "AB", 2, 19},
+		{Comment, " b ", 2, 32},
+		{Punct, "+", 2, 40},
+		{String, "c", 2, 42},
+	}
+	if len(tokens) != len(want) {
+		t.Fatalf("got %d tokens %v, want %d", len(tokens), tokens, len(want))
+	}
+	for i := range want {
+		if tokens[i] != want[i] {
+			t.Errorf("token %d = %+v, want %+v", i, tokens[i], want[i])
+		}
+	}
+}
+
+func TestTokenizeTemplate(t *testing.T) {
+	tokens, err := Tokenize("`a${ {b: 1}.b }c${d}e`", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var chunks []string
+	for _, tok := range tokens {
+		if tok.Kind == Template {
+			chunks = append(chunks, tok.Value)
+		}
+	}
+	if want := []string{"a", "c", "e"}; !slices.Equal(chunks, want) {
+		t.Errorf("template chunks = %q, want %q", chunks, want)
+	}
+}
+
+func TestTokenizeJSX(t *testing.T) {
+	src := `const el = <div className="it's" data-x={x}>
+  Don't {items.map(i => <Item key={i} {...props} />)}
+  <></>
+</div>;`
+	tokens, err := Tokenize(src, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names, idents []string
+	for _, tok := range tokens {
+		switch tok.Kind {
+		case JSX:
+			names = append(names, tok.Value)
+		case Ident:
+			idents = append(idents, tok.Value)
+		}
+	}
+	if want := []string{"div", "className", "data-x", "Item", "key", "div"}; !slices.Equal(names, want) {
+		t.Errorf("JSX names = %q, want %q", names, want)
+	}
+	if want := []string{"const", "el", "x", "items", "map", "i", "i", "props"}; !slices.Equal(idents, want) {
+		t.Errorf("identifiers = %q, want %q", idents, want)
+	}
+}
+
+func TestTokenizeGenericArrowInTSX(t *testing.T) {
+	for _, src := range []string{
+		"const f = <T,>(x: T) => x / 2",
+		"const f = <T extends object>(x: T) => x",
+	} {
+		tokens, err := Tokenize(src, true)
+		if err != nil {
+			t.Fatalf("Tokenize(%q) error: %v", src, err)
+		}
+		for _, tok := range tokens {
+			if tok.Kind == JSX {
+				t.Errorf("Tokenize(%q) lexed %q as JSX", src, tok.Value)
+			}
+		}
+	}
+}
+
+func TestTokenizeErrors(t *testing.T) {
+	tests := []struct {
+		src       string
+		jsx       bool
+		line, col int
+	}{
+		{"let s = 'abc", false, 1, 9},
+		{"let s = `abc", false, 1, 9},
+		{"x = 1;\n/* never closed", false, 2, 1},
+		{"x = /abc", false, 1, 5},
+		{"let a = 1 ¤ 2", false, 1, 11},
+		{"const a = <div>text", true, 1, 20},
+	}
+
+	for _, tt := range tests {
+		_, err := Tokenize(tt.src, tt.jsx)
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Errorf("Tokenize(%q) error = %v, want a SyntaxError", tt.src, err)
+			continue
+		}
+		if syntaxErr.Line != tt.line || syntaxErr.Col != tt.col {
+			t.Errorf("Tokenize(%q) error at %d:%d, want %d:%d (%v)", tt.src, syntaxErr.Line, syntaxErr.Col, tt.line, tt.col, err)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package jsscan
+
+type UsageKind int
+
+const (
+	// Global is a free identifier reference, e.g. "window" in window.open().
+	// Accesses on globalThis and self count as globals too.
+	Global UsageKind = iota
+	// Member is a property access, e.g. ".innerHTML" or `["innerHTML"]`.
+	Member
+	// Import is a module specifier of an import, export ... from, dynamic
+	// import() or require() call.
+	Import
+)
+
+func (k UsageKind) String() string {
+	switch k {
+	case Global:
+		return "global"
+	case Member:
+		return "member access"
+	case Import:
+		return "import"
+	}
+	return "usage"
+}
+
+// Usage is a reference found in the source. Line and Col point at the token.
+type Usage struct {
+	Kind UsageKind
+	Name string
+	Line int
+	Col  int
+}
+
+// File is the result of scanning one source file.
+type File struct {
+	Usages   []Usage
+	Comments []Token
+}
+
+// Scan tokenizes src and collects its global references, member accesses and
+// module specifiers. Strings and comments never produce usages, and neither
+// do declarations or names that resolve to one, e.g. a parameter named
+// window. jsx enables JSX syntax, as in .jsx and .tsx files.
+func Scan(src string, jsx bool) (*File, error) {
+	tokens, err := Tokenize(src, jsx)
+	if err != nil {
+		return nil, err
+	}
+
+	file := &File{}
+	code := make([]Token, 0, len(tokens))
+	for _, t := range tokens {
+		if t.Kind == Comment {
+			file.Comments = append(file.Comments, t)
+		} else {
+			code = append(code, t)
+		}
+	}
+
+	s := newScopes(code)
+	at := s.at
+	use := func(kind UsageKind, name string, t Token) {
+		file.Usages = append(file.Usages, Usage{Kind: kind, Name: name, Line: t.Line, Col: t.Col})
+	}
+
+	for i, t := range code {
+		prev, next := at(i-1), at(i+1)
+
+		switch t.Kind {
+		case Ident:
+			switch {
+			case isPunct(prev, ".", "?."):
+				use(Member, t.Value, t)
+				if isIdent(at(i-2), "globalThis", "self") && !s.declared(i-2) {
+					use(Global, t.Value, t)
+				}
+			case isPunct(next, ":") && isPunct(prev, "{", ","):
+				// Object literal key.
+			case keywords[t.Value] || s.names[i] || s.declared(i):
+			default:
+				use(Global, t.Value, t)
+			}
+
+		case String:
+			switch {
+			case isIdent(prev, "from", "import"):
+				use(Import, t.Value, t)
+			case isPunct(prev, "(") && isIdent(at(i-2), "import", "require") && !isPunct(at(i-3), "."):
+				use(Import, t.Value, t)
+			case isPunct(prev, "[") && isPunct(next, "]") && isObject(at(i-2)):
+				use(Member, t.Value, t)
+				if isIdent(at(i-2), "globalThis", "self") && !s.declared(i-2) {
+					use(Global, t.Value, t)
+				}
+			}
+		}
+	}
+
+	return file, nil
+}
+
+// keywords are never reported as globals: the reserved words, and the
+// contextual keywords like as and from, which the scanner does not tell apart
+// from identifiers.
+var keywords = map[string]bool{
+	"await": true, "break": true, "case": true, "catch": true, "class": true,
+	"const": true, "continue": true, "debugger": true, "default": true,
+	"delete": true, "do": true, "else": true, "enum": true, "export": true,
+	"extends": true, "false": true, "finally": true, "for": true,
+	"function": true, "if": true, "implements": true, "import": true,
+	"in": true, "instanceof": true, "interface": true, "let": true, "new": true,
+	"null": true, "package": true, "private": true, "protected": true,
+	"public": true, "return": true, "static": true, "super": true,
+	"switch": true, "this": true, "throw": true, "true": true, "try": true,
+	"typeof": true, "var": true, "void": true, "while": true, "with": true,
+	"yield": true, "as": true, "async": true, "from": true, "get": true,
+	"of": true, "set": true, "type": true,
+}
+
+func isIdent(t Token, values ...string) bool {
+	if t.Kind != Ident {
+		return false
+	}
+	for _, v := range values {
+		if t.Value == v {
+			return true
+		}
+	}
+	return false
+}
+
+// isObject reports whether t can end the expression before a computed member
+// access, as opposed to starting an array literal.
+func isObject(t Token) bool {
+	switch t.Kind {
+	case Ident:
+		return !regexKeywords[t.Value]
+	case String, Template, Number:
+		return true
+	case Punct:
+		return t.Value == ")" || t.Value == "]" || t.Value == "?."
+	}
+	return false
+}
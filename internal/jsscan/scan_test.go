@@ -0,0 +1,149 @@
+package jsscan
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+// usages formats the usages of src, for compact comparisons.
+func usages(t *testing.T, src string, jsx bool) []string {
+	t.Helper()
+	file, err := Scan(src, jsx)
+	if err != nil {
+		t.Fatalf("Scan(%q) error: %v", src, err)
+	}
+	var got []string
+	for _, u := range file.Usages {
+		got = append(got, fmt.Sprintf("%s %s %d:%d", u.Kind, u.Name, u.Line, u.Col))
+	}
+	return got
+}
+
+func TestScanUsages(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		jsx  bool
+		want []string
+	}{
+		{
+			name: "global and member",
+			src:  "window.open(url)",
+			want: []string{"global window 1:1", "member access open 1:8", "global url 1:13"},
+		},
+		{
+			name: "globalThis access",
+			src:  "globalThis.document",
+			want: []string{"global globalThis 1:1", "member access document 1:12", "global document 1:12"},
+		},
+		{
+			name: "computed member",
+			src:  `el["innerHTML"] = x`,
+			want: []string{"global el 1:1", "member access innerHTML 1:4", "global x 1:19"},
+		},
+		{
+			name: "array literal is not a member access",
+			src:  `f(["innerHTML"])`,
+			want: []string{"global f 1:1"},
+		},
+		{
+			name: "object keys",
+			src:  "({ window: 1, document })",
+			want: []string{"global document 1:15"},
+		},
+		{
+			name: "strings and comments",
+			src:  "// window\n'document'",
+			want: nil,
+		},
+		{
+			name: "imports",
+			src:  "import { EditorView } from '@codemirror/view'\nimport '@tauri-apps/api'\nconst m = await import('a')\nrequire('b')",
+			want: []string{
+				"import @codemirror/view 1:28",
+				"import @tauri-apps/api 2:8",
+				"import a 3:24",
+				"global require 4:1",
+				"import b 4:9",
+			},
+		},
+		{
+			name: "jsx expression",
+			src:  "const el = <div title={document.title}>{window.name}</div>",
+			jsx:  true,
+			want: []string{
+				"global document 1:24",
+				"member access title 1:33",
+				"global window 1:41",
+				"member access name 1:48",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := usages(t, tt.src, tt.jsx)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Scan(%q) usages:\n got %q\nwant %q", tt.src, got, tt.want)
+			}
+		})
+	}
+}
+
+// globals returns the names of the global references in src.
+func globals(t *testing.T, src string, jsx bool) []string {
+	t.Helper()
+	file, err := Scan(src, jsx)
+	if err != nil {
+		t.Fatalf("Scan(%q) error: %v", src, err)
+	}
+	var names []string
+	for _, u := range file.Usages {
+		if u.Kind == Global {
+			names = append(names, u.Name)
+		}
+	}
+	return names
+}
+
+func TestScanScopes(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		jsx  bool
+		want []string
+	}{
+		{"const at top level", "const document = make(); document.x()", false, []string{"make"}},
+		{"let in block", "{ let window = 1; window } window", false, []string{"window"}},
+		{"var hoists to the function", "function f() { if (a) { var window = 1 } return window }", false, []string{"a"}},
+		{"function parameter", "function f(window) { return window.x }", false, nil},
+		{"parameter default refers outside", "function f(a = document) { return a }", false, []string{"document"}},
+		{"parameter is local to the function", "function f(document) {} document", false, []string{"document"}},
+		{"typed parameters", "function f(window: Window, n?: number): void { window }", false, []string{"Window", "number"}},
+		{"destructured parameter", "function f({ a: window, ...document }, [b, , c = d]) { window; document; b; c }", false, []string{"d"}},
+		{"arrow parameter", "items.map(window => window.x); window", false, []string{"items", "window"}},
+		{"arrow with parentheses", "const f = (document, x) => document.body + x", false, nil},
+		{"arrow body ends at comma", "f(document => document, document)", false, []string{"f", "document"}},
+		{"arrow block body", "const f = async (window) => { await window.x }", false, nil},
+		{"method parameters", "const o = { go(window) { window } }", false, nil},
+		{"class members", "class A { document = document; static window() {} get x() { return this } }", false, []string{"document"}},
+		{"catch parameter", "try {} catch (window) { window } window", false, []string{"window"}},
+		{"for declarations", "for (const window of list) { window } window", false, []string{"list", "window"}},
+		{"destructuring declaration", "const { document, x: [window] } = globals; document; window", false, []string{"globals"}},
+		{"several declarators", "let a = 1, window = 2; window", false, nil},
+		{"function declaration name", "window(); function window() {}", false, nil},
+		{"imports declare bindings", "import document, { a as window, type B } from 'x'; import * as self from 'y'; document; window; self.window", false, nil},
+		{"shadowed globalThis", "const globalThis = {}; globalThis.window", false, nil},
+		{"declaration in jsx file", "const window = 1; const el = <a href={window} />", true, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := globals(t, tt.src, tt.jsx)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Scan(%q) globals = %q, want %q", tt.src, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,485 @@
+package jsscan
+
+import "sort"
+
+// scope is a block, function body or other region that declarations are
+// visible in. Start and End are token indexes, both included.
+type scope struct {
+	Start, End int
+	// Function marks function bodies and the file, which var declarations
+	// belong to.
+	Function bool
+	Parent   int
+	Names    map[string]bool
+}
+
+// function is a function, method, arrow function or catch clause whose
+// parameters are declared in the scope of its body.
+type function struct {
+	// Params are the token indexes of the parameters, from the first to the
+	// one after the last.
+	Params [2]int
+	// Arrow marks a single parameter without parentheses, at Params[0].
+	Arrow bool
+	Body  int
+}
+
+// scopes resolves identifiers to the declarations visible where they are
+// used. It works on tokens with a few rules per construct rather than a full
+// parse, which is enough to tell a local named window from the global.
+type scopes struct {
+	code []Token
+	// match holds the index of the bracket matching each bracket, or -1.
+	match []int
+	// parent holds the index of the innermost bracket around each token, or
+	// -1 at the top level.
+	parent []int
+
+	list []scope
+	// of holds the innermost scope of each token.
+	of []int
+	// names marks the identifiers that name something, like declarations
+	// and class members, instead of referring to it.
+	names map[int]bool
+}
+
+// Keywords followed by a parenthesized head and a block that are not
+// function declarations.
+var statementKeywords = map[string]bool{
+	"if": true, "for": true, "while": true, "switch": true, "with": true, "catch": true,
+}
+
+// Modifiers that may precede the name of a class member or of a TypeScript
+// parameter property.
+var memberModifiers = map[string]bool{
+	"static": true, "async": true, "get": true, "set": true, "readonly": true,
+	"public": true, "private": true, "protected": true, "declare": true,
+	"override": true, "abstract": true, "accessor": true,
+}
+
+func newScopes(code []Token) *scopes {
+	s := &scopes{
+		code:   code,
+		match:  make([]int, len(code)),
+		parent: make([]int, len(code)),
+		of:     make([]int, len(code)),
+		names:  map[int]bool{},
+	}
+	s.matchBrackets()
+	functions, classBodies := s.findScopes()
+	s.nest()
+	s.declare(functions, classBodies)
+	return s
+}
+
+func (s *scopes) at(i int) Token {
+	if i < 0 || i >= len(s.code) {
+		return Token{Kind: -1}
+	}
+	return s.code[i]
+}
+
+// declared reports whether the identifier at i resolves to a declaration.
+func (s *scopes) declared(i int) bool {
+	name := s.code[i].Value
+	for sc := s.of[i]; sc >= 0; sc = s.list[sc].Parent {
+		if s.list[sc].Names[name] {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *scopes) matchBrackets() {
+	var open []int
+	for i, t := range s.code {
+		s.match[i] = -1
+		s.parent[i] = -1
+		if len(open) > 0 {
+			s.parent[i] = open[len(open)-1]
+		}
+
+		switch {
+		case isPunct(t, "(", "[", "{"):
+			open = append(open, i)
+		case isPunct(t, ")", "]", "}") && len(open) > 0:
+			o := open[len(open)-1]
+			open = open[:len(open)-1]
+			s.match[o], s.match[i] = i, o
+			s.parent[i] = s.parent[o]
+		}
+	}
+}
+
+func (s *scopes) add(start, end int, function bool) int {
+	if end < 0 {
+		end = len(s.code) - 1
+	}
+	s.list = append(s.list, scope{Start: start, End: end, Function: function, Names: map[string]bool{}})
+	return len(s.list) - 1
+}
+
+// findScopes creates a scope for every brace, arrow function body and for
+// statement, and finds the functions and class bodies.
+func (s *scopes) findScopes() ([]function, map[int]bool) {
+	s.add(-1, len(s.code), true)
+	braces := map[int]int{}
+	for i, t := range s.code {
+		if isPunct(t, "{") {
+			braces[i] = s.add(i, s.match[i], false)
+		}
+	}
+	body := func(i int) int {
+		sc, ok := braces[i]
+		if !ok {
+			return -1
+		}
+		s.list[sc].Function = true
+		return sc
+	}
+
+	var functions []function
+	classBodies := map[int]bool{}
+	for i, t := range s.code {
+		switch {
+		case isIdent(t, "function"):
+			j := i + 1
+			if isPunct(s.at(j), "*") {
+				j++
+			}
+			if s.at(j).Kind == Ident {
+				j++
+			}
+			j = s.skipTypeParams(j)
+			if isPunct(s.at(j), "(") && s.match[j] >= 0 {
+				if sc := body(s.blockAfter(s.match[j])); sc >= 0 {
+					functions = append(functions, function{Params: [2]int{j + 1, s.match[j]}, Body: sc})
+				}
+			}
+
+		case isIdent(t, "class"):
+			for j := i + 1; j < len(s.code) && s.parent[j] == s.parent[i]; j++ {
+				if isPunct(s.code[j], "{") {
+					classBodies[j] = true
+					break
+				}
+			}
+
+		case isIdent(t, "for") && isPunct(s.at(i+1), "(") && s.match[i+1] >= 0:
+			end := s.match[i+1]
+			if b := s.blockAfter(end); b >= 0 {
+				end = s.match[b]
+			}
+			s.add(i+1, end, false)
+
+		case t.Kind == Ident && !regexKeywords[t.Value] && !isIdent(s.at(i-1), "function") &&
+			!isPunct(s.at(i-1), ".", "?.") && isPunct(s.at(s.skipTypeParams(i+1)), "("):
+			// A method, or a statement like if or catch, when a block
+			// follows the parentheses; otherwise a call.
+			j := s.skipTypeParams(i + 1)
+			if s.match[j] < 0 {
+				break
+			}
+			b := s.blockAfter(s.match[j])
+			if b < 0 || (statementKeywords[t.Value] && t.Value != "catch") {
+				break
+			}
+			if !statementKeywords[t.Value] {
+				s.names[i] = true
+			}
+			functions = append(functions, function{Params: [2]int{j + 1, s.match[j]}, Body: body(b)})
+
+		case isPunct(t, "=>"):
+			fn := function{Body: -1}
+			if isPunct(s.at(i+1), "{") {
+				fn.Body = body(i + 1)
+			}
+			if fn.Body < 0 {
+				fn.Body = s.add(i, s.exprEnd(i+1)-1, true)
+			}
+			switch prev := s.at(i - 1); {
+			case isPunct(prev, ")") && s.match[i-1] >= 0:
+				fn.Params = [2]int{s.match[i-1] + 1, i - 1}
+			case prev.Kind == Ident:
+				fn.Params = [2]int{i - 1, i}
+				fn.Arrow = true
+			default:
+				continue
+			}
+			functions = append(functions, fn)
+		}
+	}
+	return functions, classBodies
+}
+
+// blockAfter returns the index of the "{" opening the block after the ")" at
+// i, skipping a TypeScript return type, or -1 when no block follows.
+func (s *scopes) blockAfter(i int) int {
+	j := i + 1
+	if isPunct(s.at(j), ":") {
+		for j < len(s.code) && s.parent[j] == s.parent[i] && !isPunct(s.code[j], "{", ";", "=>") {
+			j++
+		}
+	}
+	if isPunct(s.at(j), "{") && s.match[j] >= 0 {
+		return j
+	}
+	return -1
+}
+
+// skipTypeParams returns the index after TypeScript type parameters starting
+// at i, or i when there are none.
+func (s *scopes) skipTypeParams(i int) int {
+	if !isPunct(s.at(i), "<") {
+		return i
+	}
+	depth := 0
+	for j := i; j < len(s.code); j++ {
+		switch {
+		case isPunct(s.code[j], "<"):
+			depth++
+		case isPunct(s.code[j], ">"):
+			depth--
+		case isPunct(s.code[j], ">>"):
+			depth -= 2
+		case isPunct(s.code[j], ";", "{", "}"):
+			return i
+		}
+		if depth <= 0 {
+			return j + 1
+		}
+	}
+	return i
+}
+
+// exprEnd returns the index after the expression starting at i: the next
+// "," or ";" beside it, a bracket closing around it, or a line break where
+// automatic semicolon insertion ends the statement.
+func (s *scopes) exprEnd(i int) int {
+	j := i
+	for ; j < len(s.code); j++ {
+		t := s.code[j]
+		if s.parent[j] == s.parent[i] && isPunct(t, ",", ";") {
+			break
+		}
+		if isPunct(t, ")", "]", "}") && s.match[j] < i {
+			break
+		}
+		if j > i && s.parent[j] == s.parent[i] && t.Line > s.code[j-1].Line &&
+			isOperand(s.code[j-1]) && t.Kind == Ident && !isIdent(t, "in", "of", "instanceof", "as", "satisfies") {
+			break
+		}
+	}
+	return j
+}
+
+// nest sets the parent of every scope and the innermost scope of every
+// token. Scopes never partially overlap.
+func (s *scopes) nest() {
+	order := make([]int, len(s.list))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		sa, sb := s.list[order[a]], s.list[order[b]]
+		if sa.Start != sb.Start {
+			return sa.Start < sb.Start
+		}
+		return sa.End > sb.End
+	})
+
+	var stack []int
+	next := 0
+	for i := range s.code {
+		for len(stack) > 0 && s.list[stack[len(stack)-1]].End < i {
+			stack = stack[:len(stack)-1]
+		}
+		for next < len(order) && s.list[order[next]].Start <= i {
+			sc := order[next]
+			next++
+			for len(stack) > 0 && s.list[stack[len(stack)-1]].End < s.list[sc].Start {
+				stack = stack[:len(stack)-1]
+			}
+			s.list[sc].Parent = -1
+			if len(stack) > 0 {
+				s.list[sc].Parent = stack[len(stack)-1]
+			}
+			stack = append(stack, sc)
+		}
+		s.of[i] = stack[len(stack)-1]
+	}
+}
+
+// declare adds every declaration to its scope.
+func (s *scopes) declare(functions []function, classBodies map[int]bool) {
+	for _, fn := range functions {
+		if fn.Arrow {
+			s.pattern(fn.Params[0], fn.Body)
+		} else {
+			s.params(fn.Params[0], fn.Params[1], fn.Body)
+		}
+	}
+
+	for i, t := range s.code {
+		if t.Kind != Ident || isPunct(s.at(i-1), ".", "?.") {
+			continue
+		}
+		next := s.at(i + 1)
+
+		switch {
+		case classBodies[s.parent[i]] && (isPunct(s.at(i-1), "{", ";", "}", "*") || memberModifiers[s.at(i-1).Value]):
+			s.names[i] = true
+
+		case isIdent(t, "var", "let", "const") && (next.Kind == Ident || isPunct(next, "{", "[")):
+			sc := s.of[i]
+			if t.Value == "var" {
+				for !s.list[sc].Function {
+					sc = s.list[sc].Parent
+				}
+			}
+			for j := i + 1; ; {
+				j = s.pattern(j, sc)
+				for isPunct(s.at(j), "!", ":") {
+					j = s.skipTo(j, s.parent[i], ",", "=", ";")
+				}
+				if isPunct(s.at(j), "=") {
+					j = s.exprEnd(j + 1)
+				}
+				if !isPunct(s.at(j), ",") {
+					break
+				}
+				j++
+			}
+
+		case isIdent(t, "function", "class") && next.Kind == Ident && !isIdent(next, "extends", "implements"):
+			s.declareName(i+1, s.of[i])
+
+		case isIdent(t, "function") && isPunct(next, "*") && s.at(i+2).Kind == Ident:
+			s.declareName(i+2, s.of[i])
+
+		case isIdent(t, "import") && !isPunct(next, "(", "."):
+			s.importClause(i + 1)
+		}
+	}
+}
+
+func (s *scopes) declareName(i, sc int) {
+	s.list[sc].Names[s.code[i].Value] = true
+	s.names[i] = true
+}
+
+// params declares the parameters between from and to in scope sc.
+func (s *scopes) params(from, to, sc int) {
+	for j := from; j < to; {
+		for memberModifiers[s.at(j).Value] && (s.at(j+1).Kind == Ident || isPunct(s.at(j+1), "{", "[")) {
+			j++
+		}
+		if isPunct(s.at(j), "...") {
+			j++
+		}
+		if !isIdent(s.at(j), "this") {
+			j = s.pattern(j, sc)
+		}
+		// Skip the optional mark, type annotation and default value.
+		j = s.skipTo(j, from-1, ",") + 1
+	}
+}
+
+// pattern declares the names bound by the binding pattern at i in scope sc
+// and returns the index after it.
+func (s *scopes) pattern(i, sc int) int {
+	t := s.at(i)
+	switch {
+	case t.Kind == Ident:
+		s.declareName(i, sc)
+		return i + 1
+
+	case isPunct(t, "[") && s.match[i] >= 0:
+		for j := i + 1; j < s.match[i]; {
+			if isPunct(s.code[j], "...") {
+				j++
+			}
+			if !isPunct(s.code[j], ",") {
+				j = s.pattern(j, sc)
+			}
+			j = s.skipTo(j, i, ",") + 1
+		}
+		return s.match[i] + 1
+
+	case isPunct(t, "{") && s.match[i] >= 0:
+		for j := i + 1; j < s.match[i]; {
+			switch {
+			case isPunct(s.code[j], "..."):
+				j = s.pattern(j+1, sc)
+			case isPunct(s.at(j+1), ":"):
+				// The key is a property name.
+				s.names[j] = true
+				j = s.pattern(j+2, sc)
+			case isPunct(s.code[j], "[") && s.match[j] >= 0 && isPunct(s.at(s.match[j]+1), ":"):
+				j = s.pattern(s.match[j]+2, sc)
+			default:
+				j = s.pattern(j, sc)
+			}
+			j = s.skipTo(j, i, ",") + 1
+		}
+		return s.match[i] + 1
+	}
+	return i + 1
+}
+
+// skipTo returns the index of the first of values directly inside the
+// bracket at open at or after i, or of the bracket closing open.
+func (s *scopes) skipTo(i, open int, values ...string) int {
+	for ; i < len(s.code); i++ {
+		if s.parent[i] == open && isPunct(s.code[i], values...) {
+			return i
+		}
+		if open >= 0 && i == s.match[open] {
+			return i
+		}
+		if open < 0 && s.parent[i] < 0 && isPunct(s.code[i], ")", "]", "}") {
+			return i
+		}
+	}
+	return i
+}
+
+// importClause declares the bindings of the import statement whose clause
+// starts at i, all in the file's scope.
+func (s *scopes) importClause(i int) {
+	if isIdent(s.at(i), "type") && !isIdent(s.at(i+1), "from") && !isPunct(s.at(i+1), ",") {
+		i++
+	}
+	for {
+		switch t := s.at(i); {
+		case t.Kind == Ident && !isIdent(t, "from"):
+			s.declareName(i, 0)
+			i++
+		case isPunct(t, "*") && isIdent(s.at(i+1), "as") && s.at(i+2).Kind == Ident:
+			s.declareName(i+2, 0)
+			i += 3
+		case isPunct(t, "{") && s.match[i] >= 0:
+			for j := i + 1; j < s.match[i]; j++ {
+				if isIdent(s.code[j], "type") && s.at(j+1).Kind == Ident && !isIdent(s.at(j+1), "as") {
+					continue
+				}
+				if s.code[j].Kind != Ident || isIdent(s.code[j], "as") {
+					continue
+				}
+				if isIdent(s.at(j+1), "as") {
+					// The imported name is not a local binding.
+					s.names[j] = true
+					continue
+				}
+				s.declareName(j, 0)
+			}
+			i = s.match[i] + 1
+		default:
+			return
+		}
+		if !isPunct(s.at(i), ",") {
+			return
+		}
+		i++
+	}
+}
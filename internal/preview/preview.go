@@ -0,0 +1,134 @@
+// Package preview serves a live preview of a theme under development: a page
+// of representative Markdown elements styled by the theme, reloaded through
+// server-sent events when the theme changes.
+package preview
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"inkdown-cli/internal/validate"
+)
+
+//go:embed preview.html
+var pageHTML string
+
+var page = template.Must(template.New("preview").Parse(pageHTML))
+
+// Change is sent to the page when theme files change.
+type Change struct {
+	Files []string `json:"files"`
+	Valid bool     `json:"valid"`
+}
+
+// Server serves the preview of the theme in Dir.
+type Server struct {
+	Dir string
+
+	mu      sync.Mutex
+	clients map[chan Change]struct{}
+}
+
+func New(dir string) *Server {
+	return &Server{Dir: dir, clients: map[chan Change]struct{}{}}
+}
+
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", s.servePage)
+	mux.HandleFunc("/theme/", s.serveCSS)
+	mux.HandleFunc("/events", s.serveEvents)
+	return mux
+}
+
+// Notify tells every open page about a change.
+func (s *Server) Notify(change Change) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for client := range s.clients {
+		select {
+		case client <- change:
+		default:
+			// The page is not keeping up; it gets the next change.
+		}
+	}
+}
+
+func (s *Server) servePage(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+
+	manifest, err := validate.LoadThemeManifest(s.Dir)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	page.Execute(w, map[string]interface{}{
+		"Name":  manifest.Name,
+		"Modes": manifest.CSSModes(),
+	})
+}
+
+// serveCSS serves the stylesheets of the modes in theme.json and nothing else.
+func (s *Server) serveCSS(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, "/theme/")
+	mode, ok := strings.CutSuffix(name, ".css")
+
+	manifest, err := validate.LoadThemeManifest(s.Dir)
+	if !ok || err != nil || !slices.Contains(manifest.CSSModes(), mode) {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/css; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	http.ServeFile(w, r, filepath.Join(s.Dir, name))
+}
+
+func (s *Server) serveEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Connection", "keep-alive")
+	fmt.Fprint(w, ": connected\n\n")
+	flusher.Flush()
+
+	client := make(chan Change, 8)
+	s.mu.Lock()
+	s.clients[client] = struct{}{}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.clients, client)
+		s.mu.Unlock()
+	}()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case change := <-client:
+			data, _ := json.Marshal(change)
+			fmt.Fprintf(w, "event: change\ndata: %s\n\n", data)
+			flusher.Flush()
+		}
+	}
+}
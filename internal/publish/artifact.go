@@ -0,0 +1,179 @@
+package publish
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"inkdown-cli/config"
+	"inkdown-cli/internal/credentials"
+	"inkdown-cli/internal/github"
+	"inkdown-cli/internal/registry"
+	"inkdown-cli/utils"
+)
+
+// artifact describes a publishable project (plugin or theme): what goes into
+// its GitHub release and how it is registered in inkdown-community.
+type artifact struct {
+	Kind        string // "plugin" or "theme", used in messages
+	Dir         string
+	Name        string
+	Version     string
+	Description string
+	Assets      []string
+	// Build, when set, produces the assets before they are released.
+	Build func() error
+
+	// Owner and Repo identify the author's GitHub repository.
+	Owner string
+	Repo  string
+
+	RegistryFile string
+	// UpdateRegistry returns the registry file contents with this artifact
+	// added or updated.
+	UpdateRegistry func(existing string) (string, error)
+	CommitMessage  string
+	PRTitle        string
+	PRBody         string
+}
+
+func (a *artifact) tag() string {
+	return tagFor(a.Version)
+}
+
+func (a *artifact) branch() string {
+	return fmt.Sprintf("add-%s/%s", a.Kind, a.Repo)
+}
+
+// authenticate returns a GitHub client holding a validated token. Tokens from
+// the environment win over the one stored for the active profile by
+// `ink auth github`; publish never starts a login itself.
+func authenticate() (*github.Client, error) {
+	env := config.LoadEnv()
+	client := github.NewClientFromEnv(env, "")
+
+	if env.GitHubToken != "" {
+		client.Token = env.GitHubToken
+		if err := client.ValidateToken(); err != nil {
+			return nil, fmt.Errorf("the GitHub token from INK_GITHUB_TOKEN/GITHUB_TOKEN is invalid: %v", err)
+		}
+		utils.Info("Using GitHub token from the environment")
+		return client, nil
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %v", err)
+	}
+	profile := cfg.ActiveProfile()
+
+	store, err := credentials.Open()
+	if err != nil {
+		return nil, err
+	}
+
+	hint := "run 'ink auth github'"
+	if profile != config.DefaultProfile {
+		hint = fmt.Sprintf("run 'ink auth github --profile %s'", profile)
+	}
+
+	token, backend, err := store.Get(credentials.Key(profile, credentials.GitHubToken))
+	if errors.Is(err, credentials.ErrNotFound) {
+		return nil, fmt.Errorf("no GitHub account connected to profile '%s': %s or set INK_GITHUB_TOKEN", profile, hint)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	client.Token = token
+	if err := client.ValidateToken(); err != nil {
+		return nil, fmt.Errorf("the stored GitHub token of profile '%s' is invalid or expired: %s again", profile, hint)
+	}
+	utils.Info("Using GitHub token of profile '%s' from the %s credential store", profile, backend.Name())
+
+	return client, nil
+}
+
+// detectRepo returns the owner and name of the project's GitHub repository,
+// read from the git origin remote and falling back to the authenticated user.
+func detectRepo(client *github.Client, dir string, name string) (string, string, error) {
+	// Get Repo Info from Git Config
+	cmd := exec.Command("git", "remote", "get-url", "origin")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	var repoOwner, repoName string
+
+	if err == nil {
+		remoteURL := strings.TrimSpace(string(out))
+		// Handle SSH: git@github.com:owner/repo.git
+		// Handle HTTPS: https://github.com/owner/repo.git
+
+		remoteURL = strings.TrimSuffix(remoteURL, ".git")
+
+		parts := strings.Split(remoteURL, "/")
+		if len(parts) >= 2 {
+			repoName = parts[len(parts)-1]
+			repoOwner = parts[len(parts)-2]
+
+			// Handle potential SSH prefix in owner (git@github.com:owner)
+			if strings.Contains(repoOwner, ":") {
+				ownerParts := strings.Split(repoOwner, ":")
+				repoOwner = ownerParts[len(ownerParts)-1]
+			}
+		}
+	}
+
+	// Fallback or validation
+	if repoName == "" || repoOwner == "" {
+		// Try to use authenticated user and guessed name as fallback, but warn
+		var err error
+		repoOwner, err = client.GetGitHubUsername()
+		if err != nil {
+			return "", "", err
+		}
+		repoName = strings.ReplaceAll(name, " ", "-")
+		utils.Warn("Could not detect git remote. defaulting to %s/%s", repoOwner, repoName)
+	}
+
+	return repoOwner, repoName, nil
+}
+
+func tagFor(version string) string {
+	if strings.HasPrefix(version, "v") {
+		return version
+	}
+	return "v" + version
+}
+
+func contentTypeFor(asset string) string {
+	switch {
+	case strings.HasSuffix(asset, ".json"):
+		return "application/json"
+	case strings.HasSuffix(asset, ".css"):
+		return "text/css"
+	default:
+		return "application/javascript"
+	}
+}
+
+// upsertRegistry adds entry to the registry file contents, or updates the
+// existing entry with the same id.
+func upsertRegistry(existing string, entry registry.Entry) (string, error) {
+	reg, err := registry.Parse(existing)
+	if err != nil {
+		return "", err
+	}
+
+	added, err := reg.Upsert(entry)
+	if err != nil {
+		return "", err
+	}
+	if added {
+		utils.Info("Adding %s to the registry", entry.ID)
+	} else {
+		utils.Info("Updating existing registry entry %s", entry.ID)
+	}
+
+	return reg.String()
+}
@@ -0,0 +1,389 @@
+package publish
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"inkdown-cli/internal/diff"
+	"inkdown-cli/internal/github"
+	"inkdown-cli/utils"
+)
+
+// Publish steps, in execution order. Their names are recorded in the state
+// file as they complete.
+const (
+	stepBuild    = "build"
+	stepRelease  = "release"
+	stepAssets   = "assets"
+	stepFork     = "fork"
+	stepBranch   = "branch"
+	stepRegistry = "registry"
+	stepPR       = "pr"
+)
+
+// errAborted stops the pipeline without reporting a failure, e.g. when the
+// user declines to overwrite an existing release.
+var errAborted = errors.New("aborted")
+
+type step struct {
+	Name string
+	Run  func() error
+}
+
+type pipeline struct {
+	client *github.Client
+	a      *artifact
+	state  *State
+	opts   Options
+}
+
+// Result describes a finished publish run, or the plan of a dry run.
+type Result struct {
+	Kind       string   `json:"kind"`
+	Name       string   `json:"name"`
+	Version    string   `json:"version"`
+	Tag        string   `json:"tag"`
+	ReleaseURL string   `json:"release_url,omitempty"`
+	Assets     []string `json:"assets"`
+	PRURL      string   `json:"pr_url,omitempty"`
+	DryRun     bool     `json:"dry_run,omitempty"`
+	Aborted    bool     `json:"aborted,omitempty"`
+	Plan       *Plan    `json:"plan,omitempty"`
+}
+
+// publishArtifact releases the artifact and opens the registry pull request.
+// Each step is checkpointed so that a failed run can be continued with
+// Options.Resume. In dry-run mode it prints the plan and returns it in the
+// result.
+func publishArtifact(client *github.Client, a *artifact, opts Options) (*Result, error) {
+	result := &Result{
+		Kind:    a.Kind,
+		Name:    a.Name,
+		Version: a.Version,
+		Tag:     a.tag(),
+		DryRun:  opts.DryRun,
+	}
+
+	if opts.PRBodyFile != "" {
+		body, err := os.ReadFile(opts.PRBodyFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not read PR body file: %v", err)
+		}
+		if strings.TrimSpace(string(body)) == "" {
+			return nil, fmt.Errorf("PR body file %s is empty", opts.PRBodyFile)
+		}
+		a.PRBody = string(body)
+	}
+
+	if opts.DryRun {
+		if a.Build != nil {
+			if err := a.Build(); err != nil {
+				return nil, err
+			}
+		}
+		plan, err := planArtifact(client, a)
+		if err != nil {
+			return nil, err
+		}
+		result.Assets = a.Assets
+		result.Plan = plan
+		return result, nil
+	}
+
+	state, err := LoadState(a.Kind, a.Owner+"/"+a.Repo, a.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Resume {
+		if state.Started() {
+			utils.Info("Resuming publish of %s v%s (completed: %s)", a.Name, a.Version, strings.Join(state.Completed, ", "))
+		}
+	} else if state.Started() {
+		utils.Warn("Discarding the checkpoint of a previous publish of %s v%s. Use --resume to continue it instead.", a.Name, a.Version)
+		if err := state.Reset(); err != nil {
+			return nil, err
+		}
+	}
+
+	p := &pipeline{client: client, a: a, state: state, opts: opts}
+	for _, s := range p.steps() {
+		if state.Done(s.Name) {
+			utils.Note("Skipping %s step (already completed)", s.Name)
+			continue
+		}
+
+		if err := s.Run(); err != nil {
+			if errors.Is(err, errAborted) {
+				utils.Info("Aborting.")
+				result.Aborted = true
+				return result, nil
+			}
+			return nil, fmt.Errorf("%s step failed: %v (run again with --resume to continue from here)", s.Name, err)
+		}
+
+		if err := state.complete(s.Name); err != nil {
+			return nil, fmt.Errorf("could not save publish state: %v", err)
+		}
+	}
+
+	result.ReleaseURL = state.ReleaseURL
+	result.Assets = a.Assets
+	result.PRURL = state.PRURL
+	return result, nil
+}
+
+func (p *pipeline) steps() []step {
+	var steps []step
+	if p.a.Build != nil {
+		steps = append(steps, step{stepBuild, p.a.Build})
+	}
+	return append(steps,
+		step{stepRelease, p.release},
+		step{stepAssets, p.uploadAssets},
+		step{stepFork, p.fork},
+		step{stepBranch, p.branch},
+		step{stepRegistry, p.updateRegistry},
+		step{stepPR, p.pullRequest},
+	)
+}
+
+func (p *pipeline) release() error {
+	a := p.a
+	tagName := a.tag()
+
+	utils.Info("Checking for existing release %s in %s/%s...", tagName, a.Owner, a.Repo)
+	existingRelease, err := p.client.GetReleaseByTag(a.Owner, a.Repo, tagName)
+	if err != nil {
+		return err
+	}
+
+	if existingRelease != nil && existingRelease.ID == p.state.ReleaseID {
+		utils.Info("Reusing release %s created by the previous run", tagName)
+		p.state.ReleaseURL = existingRelease.HTMLURL
+		return nil
+	}
+
+	if existingRelease != nil {
+		utils.Warn("Release %s already exists!", tagName)
+
+		switch {
+		case p.opts.Overwrite:
+			utils.Info("Overwriting it (--overwrite)")
+		case p.opts.Yes || !utils.IsInteractive():
+			return fmt.Errorf("release %s already exists; pass --overwrite to replace it", tagName)
+		default:
+			reader := bufio.NewReader(os.Stdin)
+			utils.Prompt("Do you want to overwrite it? ALL ASSETS WILL BE REPLACED. (y/N): ")
+
+			answer, _ := reader.ReadString('\n')
+			answer = strings.TrimSpace(strings.ToLower(answer))
+
+			if answer != "y" && answer != "yes" {
+				return errAborted
+			}
+		}
+
+		utils.Info("Deleting old release...")
+		if err := p.client.DeleteRelease(a.Owner, a.Repo, existingRelease.ID); err != nil {
+			return fmt.Errorf("failed to delete release: %v", err)
+		}
+		_ = p.client.DeleteTag(a.Owner, a.Repo, tagName)
+	}
+
+	utils.Info("Creating release %s...", tagName)
+	newRelease, err := p.client.CreateRelease(
+		a.Owner,
+		a.Repo,
+		tagName,
+		fmt.Sprintf("%s %s", a.Name, a.Version),
+		a.Description,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create release: %v", err)
+	}
+
+	p.state.ReleaseID = newRelease.ID
+	p.state.ReleaseURL = newRelease.HTMLURL
+	p.state.UploadedAssets = nil
+	return p.state.Save()
+}
+
+func (p *pipeline) uploadAssets() error {
+	a := p.a
+	for _, asset := range a.Assets {
+		if slices.Contains(p.state.UploadedAssets, asset) {
+			utils.Note("Skipping %s (already uploaded)", asset)
+			continue
+		}
+
+		assetPath := filepath.Join(a.Dir, asset)
+		utils.Info("Uploading %s...", asset)
+
+		if err := p.client.UploadReleaseAsset(a.Owner, a.Repo, p.state.ReleaseID, assetPath, contentTypeFor(asset)); err != nil {
+			return fmt.Errorf("failed to upload %s: %v", asset, err)
+		}
+
+		p.state.UploadedAssets = append(p.state.UploadedAssets, asset)
+		if err := p.state.Save(); err != nil {
+			return err
+		}
+	}
+
+	utils.Success("Release published successfully!")
+	return nil
+}
+
+func (p *pipeline) fork() error {
+	utils.Info("Proceeding to update Community Registry...")
+
+	fork, err := p.client.ForkRepo()
+	if err != nil {
+		return err
+	}
+
+	p.state.Fork = fork
+	return nil
+}
+
+func (p *pipeline) branch() error {
+	branch := p.a.branch()
+
+	// Branch from the community repository's main, not the fork's, which
+	// is only as recent as the last sync.
+	sha, err := p.client.GetBranchSHA(github.ORG_NAME + "/" + github.REPO_NAME)
+	if err != nil {
+		return err
+	}
+
+	err = p.client.CreateBranch(p.state.Fork, branch, sha)
+	if errors.Is(err, github.ErrRefExists) {
+		// Left over from an earlier publish: start it again from the
+		// current base so the registry change is not built on stale data.
+		utils.Info("Branch %s already exists in %s, resetting it to %s/%s@%s", branch, p.state.Fork, github.ORG_NAME, github.REPO_NAME, github.BRANCH)
+		err = p.client.ResetBranch(p.state.Fork, branch, sha)
+	}
+	if err != nil {
+		return err
+	}
+
+	p.state.Branch = branch
+	return nil
+}
+
+func (p *pipeline) updateRegistry() error {
+	a := p.a
+
+	content, contentSha, err := p.client.GetFileContent(p.state.Fork, p.state.Branch, a.RegistryFile)
+	if err != nil {
+		return err
+	}
+
+	utils.Info("Updating %s...", a.RegistryFile)
+	updated, err := a.UpdateRegistry(content)
+	if err != nil {
+		return err
+	}
+
+	if updated == content {
+		utils.Info("%s is already up to date on %s", a.RegistryFile, p.state.Branch)
+		return nil
+	}
+
+	return p.client.UpdateFile(
+		p.state.Fork,
+		p.state.Branch,
+		a.RegistryFile,
+		updated,
+		contentSha,
+		a.CommitMessage,
+	)
+}
+
+func (p *pipeline) pullRequest() error {
+	forkOwner, _, _ := strings.Cut(p.state.Fork, "/")
+	head := forkOwner + ":" + p.state.Branch
+
+	existing, err := p.client.FindOpenPR(head)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		utils.Info("Pull request #%d is already open for %s, reusing it", existing.Number, head)
+		p.state.PRURL = existing.HTMLURL
+		return nil
+	}
+
+	utils.Note("Creating the following PR:\n%s", p.a.PRBody)
+
+	title := p.opts.PRTitle
+	if title == "" && p.opts.Yes {
+		title = p.a.PRTitle
+	} else if title == "" {
+		reader := bufio.NewReader(os.Stdin)
+		utils.Prompt("Please provide a title for your PR (default: %s): ", p.a.PRTitle)
+		title, _ = reader.ReadString('\n')
+		title = strings.TrimSpace(title)
+		if title == "" {
+			title = p.a.PRTitle
+		}
+	}
+
+	prURL, err := p.client.CreatePR(head, title, p.a.PRBody)
+	if err != nil {
+		return err
+	}
+
+	p.state.PRURL = prURL
+	return nil
+}
+
+// planArtifact prints what publishArtifact would do, using only read-only
+// API calls.
+func planArtifact(client *github.Client, a *artifact) (*Plan, error) {
+	tagName := a.tag()
+
+	utils.Info("Checking for existing release %s in %s/%s...", tagName, a.Owner, a.Repo)
+	existingRelease, err := client.GetReleaseByTag(a.Owner, a.Repo, tagName)
+	if err != nil {
+		return nil, err
+	}
+
+	content, _, err := client.GetFileContent(github.ORG_NAME+"/"+github.REPO_NAME, github.BRANCH, a.RegistryFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %v", a.RegistryFile, err)
+	}
+
+	updated, err := a.UpdateRegistry(content)
+	if err != nil {
+		return nil, err
+	}
+
+	// The branch is pushed to the authenticated user's fork.
+	forkOwner, err := client.GetGitHubUsername()
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &Plan{
+		Repo:          a.Owner + "/" + a.Repo,
+		Tag:           tagName,
+		ReleaseName:   fmt.Sprintf("%s %s", a.Name, a.Version),
+		DeleteRelease: existingRelease,
+		Branch:        forkOwner + ":" + a.branch(),
+		RegistryFile:  a.RegistryFile,
+		RegistryDiff:  diff.Unified("a/"+a.RegistryFile, "b/"+a.RegistryFile, content, updated, diff.DefaultContext),
+		PRBody:        a.PRBody,
+	}
+	if err := plan.AddAssets(a.Dir, a.Assets); err != nil {
+		return nil, err
+	}
+
+	plan.Print()
+	return plan, nil
+}
@@ -0,0 +1,85 @@
+package publish
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"inkdown-cli/internal/github"
+	"inkdown-cli/utils"
+)
+
+// Plan describes everything a publish run would change. It is built by a
+// dry run from read-only API calls and printed instead of being executed.
+type Plan struct {
+	Repo          string          `json:"repo"`
+	Tag           string          `json:"tag"`
+	ReleaseName   string          `json:"release_name"`
+	DeleteRelease *github.Release `json:"delete_release,omitempty"`
+	Assets        []PlannedAsset  `json:"assets"`
+
+	Branch       string `json:"branch"`
+	RegistryFile string `json:"registry_file"`
+	// RegistryDiff is the unified diff of the registry file, empty when the
+	// entry is already up to date.
+	RegistryDiff string `json:"registry_diff"`
+	PRBody       string `json:"pr_body"`
+}
+
+type PlannedAsset struct {
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// AddAssets records the size and SHA-256 of each asset in dir.
+func (p *Plan) AddAssets(dir string, assets []string) error {
+	for _, asset := range assets {
+		f, err := os.Open(filepath.Join(dir, asset))
+		if err != nil {
+			return fmt.Errorf("could not read asset %s: %v", asset, err)
+		}
+
+		h := sha256.New()
+		size, err := io.Copy(h, f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("could not hash asset %s: %v", asset, err)
+		}
+
+		p.Assets = append(p.Assets, PlannedAsset{
+			Name:   asset,
+			Size:   size,
+			SHA256: hex.EncodeToString(h.Sum(nil)),
+		})
+	}
+	return nil
+}
+
+func (p *Plan) Print() {
+	utils.Note("Dry run: no changes were made. The publish would:")
+
+	if p.DeleteRelease != nil {
+		utils.Warn("Delete release %s (id %d) and its tag in %s", p.DeleteRelease.TagName, p.DeleteRelease.ID, p.Repo)
+	}
+
+	utils.Info("Create release %q with tag %s in %s", p.ReleaseName, p.Tag, p.Repo)
+	for _, asset := range p.Assets {
+		utils.Printf("  %-16s %10d bytes  sha256:%s\n", asset.Name, asset.Size, asset.SHA256)
+	}
+
+	utils.Info("Fork %s/%s and push branch %s", github.ORG_NAME, github.REPO_NAME, p.Branch)
+
+	utils.Info("Update %s:", p.RegistryFile)
+	if p.RegistryDiff != "" {
+		utils.Printf("%s", p.RegistryDiff)
+	} else {
+		utils.Println("  (no changes)")
+	}
+
+	utils.Info("Open a pull request against %s/%s with body:", github.ORG_NAME, github.REPO_NAME)
+	utils.Println(p.PRBody)
+}
@@ -1,17 +1,14 @@
 package publish
 
 import (
-	"bufio"
 	"encoding/json"
 	"fmt"
 	"inkdown-cli/config"
-	"inkdown-cli/internal/github"
+	"inkdown-cli/internal/build"
+	"inkdown-cli/internal/registry"
 	"inkdown-cli/utils"
 	"os"
-	"os/exec"
 	"path/filepath"
-	"runtime"
-	"strings"
 )
 
 type PackageJSON interface {
@@ -21,6 +18,7 @@ type PackageJSON interface {
 }
 
 type Package struct {
+	ID          string `json:"id"`
 	Name        string `json:"name"`
 	Version     string `json:"version"`
 	Description string `json:"description"`
@@ -39,268 +37,165 @@ func (p Package) GetDescription() string {
 	return p.Description
 }
 
-func PublishPlugin(dir *string) (string, error) {
-	utils.Info("Started the publish process...")
+// Options controls how a publish run behaves.
+type Options struct {
+	// DryRun performs every read-only step and prints the plan instead of
+	// creating releases, forks, commits or pull requests.
+	DryRun bool
+	// Resume continues an interrupted publish of the same version, skipping
+	// the steps recorded as completed in its state file.
+	Resume bool
+
+	// Yes never prompts, using --pr-title or a generated title for the pull
+	// request. It does not imply Overwrite.
+	Yes bool
+	// Overwrite replaces an existing release with the same tag without asking.
+	Overwrite bool
+	// PRTitle and PRBodyFile replace the pull request title prompt and the
+	// generated pull request body.
+	PRTitle    string
+	PRBodyFile string
+
+	// PackageManager overrides the package manager detected for installing
+	// plugin dependencies and running the build script.
+	PackageManager string
+}
 
-	manifestPath := filepath.Join(*dir, "manifest.json")
-	rawManifest, err := os.ReadFile(manifestPath)
-	if err != nil {
-		return "", fmt.Errorf("could not read manifest.json: %v (make sure you are in the plugin root)", err)
+// check fails fast when a run would need to prompt without a terminal.
+func (o Options) check() error {
+	if o.DryRun || o.Yes || o.PRTitle != "" || utils.IsInteractive() {
+		return nil
 	}
+	return fmt.Errorf("stdin is not a terminal and publishing would prompt for a PR title: pass --pr-title or --yes")
+}
 
-	var manifest Package
-	if err := json.Unmarshal(rawManifest, &manifest); err != nil {
-		return "", fmt.Errorf("invalid manifest.json: %v", err)
+func PublishPlugin(dir *string, opts Options) (*Result, error) {
+	if err := opts.check(); err != nil {
+		return nil, err
 	}
 
-	if manifest.Version == "" {
-		return "", fmt.Errorf("manifest.json missing 'version'")
-	}
-	if manifest.Name == "" {
-		return "", fmt.Errorf("manifest.json missing 'name'")
+	utils.Info("Started the publish process...")
+
+	manifest, err := loadPluginManifest(*dir)
+	if err != nil {
+		return nil, err
 	}
 
 	utils.Info("Detected Plugin: %s v%s", manifest.Name, manifest.Version)
 
-	// 1.5 Build Plugin
-	pkgJsonPath := filepath.Join(*dir, "package.json")
-	if _, err := os.Stat(pkgJsonPath); err == nil {
-		utils.Info("Building plugin...")
-		
-		// npm install
-		utils.Info("Running 'npm install'...")
-		installCmd := exec.Command("bun", "install")
-		installCmd.Dir = *dir
-		installCmd.Stdout = os.Stdout
-		installCmd.Stderr = os.Stderr
-		if err := installCmd.Run(); err != nil {
-			return "", fmt.Errorf("failed to run 'npm install': %v", err)
-		}
-
-		// npm run build
-		utils.Info("Running 'npm run build'...")
-		buildCmd := exec.Command("bun", "run", "build")
-		buildCmd.Dir = *dir
-		buildCmd.Stdout = os.Stdout
-		buildCmd.Stderr = os.Stderr
-		if err := buildCmd.Run(); err != nil {
-			return "", fmt.Errorf("failed to run 'npm run build': %v", err)
-		}
-
-		// Verify main.js
-		mainJsPath := filepath.Join(*dir, "main.js")
-		if _, err := os.Stat(mainJsPath); os.IsNotExist(err) {
-			return "", fmt.Errorf("build completed but 'main.js' was not found")
-		}
-		utils.Success("Build successful!")
-	} else {
-		utils.Warn("No package.json found. Skipping build step (expecting pre-built assets).")
+	client, err := authenticate()
+	if err != nil {
+		return nil, err
 	}
 
-	env := config.LoadEnv()
-	token, err := github.LoadToken()
-	if err == nil {
-		if err := github.ValidateToken(token); err == nil {
-			fmt.Println("Using saved GitHub token")
-		} else {
-			token = ""
+	username, userRepoName, err := detectRepo(client, *dir, manifest.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := registry.Entry{
+		ID:          manifest.ID,
+		Name:        manifest.Name,
+		Author:      username,
+		Version:     manifest.Version,
+		Description: manifest.Description,
+		Repo:        username + "/" + userRepoName,
+	}
+
+	a := &artifact{
+		Kind:         "plugin",
+		Dir:          *dir,
+		Name:         manifest.Name,
+		Version:      manifest.Version,
+		Description:  manifest.Description,
+		Assets:       pluginAssets(*dir),
+		Owner:        username,
+		Repo:         userRepoName,
+		RegistryFile: "plugins.json",
+		UpdateRegistry: func(existing string) (string, error) {
+			return upsertRegistry(existing, entry)
+		},
+		CommitMessage: fmt.Sprintf("feat: add plugin %s v%s", manifest.Name, manifest.Version),
+		PRTitle:       fmt.Sprintf("Add plugin %s v%s", manifest.Name, manifest.Version),
+		PRBody:        pluginPRBody(manifest),
+	}
+	a.Build = func() error {
+		if err := buildPlugin(*dir, opts.PackageManager); err != nil {
+			return err
 		}
+		// The build may emit styles.css, so list the assets again.
+		a.Assets = pluginAssets(*dir)
+		return nil
 	}
 
-	if token == "" {
-		code, err := github.RequestDeviceCode(env.ClientID)
-		if err != nil {
-			return "", err
-		}
-
-		utils.Info("To authorize this application, open: %s", code.VerificationURI)
-		utils.Info("And enter the code: %s", code.UserCode)
-
-		go func() {
-			var cmd *exec.Cmd
-			switch runtime.GOOS {
-			case "darwin":
-				cmd = exec.Command("open", code.VerificationURI)
-			case "windows":
-				cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", code.VerificationURI)
-			default:
-				cmd = exec.Command("xdg-open", code.VerificationURI)
-			}
-			_ = cmd.Start()
-		}()
+	return publishArtifact(client, a, opts)
+}
 
-		token, err = github.PollForToken(env.ClientID, code.DeviceCode, code.Interval)
-		if err != nil {
-			return "", err
-		}
-		_ = github.SaveToken(token)
+func loadPluginManifest(dir string) (*Package, error) {
+	manifestPath := filepath.Join(dir, "manifest.json")
+	rawManifest, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return nil, fmt.Errorf("could not read manifest.json: %v (make sure you are in the plugin root)", err)
 	}
 
-	if err := github.ValidateToken(token); err != nil {
-		return "", err
+	var manifest Package
+	if err := json.Unmarshal(rawManifest, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid manifest.json: %v", err)
 	}
 
-	// 3. User & Repo Info
-	// Get Repo Info from Git Config
-	cmd := exec.Command("git", "remote", "get-url", "origin")
-	cmd.Dir = *dir
-	out, err := cmd.Output()
-	var repoOwner, repoName string
-
-	if err == nil {
-		remoteURL := strings.TrimSpace(string(out))
-		// Handle SSH: git@github.com:owner/repo.git
-		// Handle HTTPS: https://github.com/owner/repo.git
-		
-		remoteURL = strings.TrimSuffix(remoteURL, ".git")
-		
-		parts := strings.Split(remoteURL, "/")
-		if len(parts) >= 2 {
-			repoName = parts[len(parts)-1]
-			repoOwner = parts[len(parts)-2]
-			
-			// Handle potential SSH prefix in owner (git@github.com:owner)
-			if strings.Contains(repoOwner, ":") {
-				ownerParts := strings.Split(repoOwner, ":")
-				repoOwner = ownerParts[len(ownerParts)-1]
-			}
-		}
+	if manifest.ID == "" {
+		return nil, fmt.Errorf("manifest.json missing 'id'")
 	}
-
-	// Fallback or validation
-	if repoName == "" || repoOwner == "" {
-		// Try to use authenticated user and guessed name as fallback, but warn
-		var err error
-		repoOwner, err = github.GetGitHubUsername(token)
-		if err != nil {
-			return "", err
-		}
-		repoName = strings.ReplaceAll(manifest.Name, " ", "-")
-		utils.Warn("Could not detect git remote. defaulting to %s/%s", repoOwner, repoName)
+	if manifest.Version == "" {
+		return nil, fmt.Errorf("manifest.json missing 'version'")
 	}
-
-	username := repoOwner
-	userRepoName := repoName
-
-	tagName := "v" + manifest.Version
-	if strings.HasPrefix(manifest.Version, "v") {
-		tagName = manifest.Version
+	if manifest.Name == "" {
+		return nil, fmt.Errorf("manifest.json missing 'name'")
 	}
 
-	utils.Info("Checking for existing release %s in %s/%s...", tagName, username, userRepoName)
-	existingRelease, err := github.GetReleaseByTag(token, username, userRepoName, tagName)
-
-	if existingRelease != nil {
-		utils.Warn("Release %s already exists!", tagName)
-		reader := bufio.NewReader(os.Stdin)
-		utils.Prompt("Do you want to overwrite it? ALL ASSETS WILL BE REPLACED. (y/N): ")
-
-		answer, _ := reader.ReadString('\n')
-		answer = strings.TrimSpace(strings.ToLower(answer))
-
-		if answer != "y" && answer != "yes" {
-			utils.Info("Aborting.")
-			return "", nil
-		}
-
-		utils.Info("Deleting old release...")
-		if err := github.DeleteRelease(token, username, userRepoName, existingRelease.ID); err != nil {
-			return "", fmt.Errorf("failed to delete release: %v", err)
-		}
-		_ = github.DeleteTag(token, username, userRepoName, tagName)
-	}
+	return &manifest, nil
+}
 
-	utils.Info("Creating release %s...", tagName)
-	newRelease, err := github.CreateRelease(
-		token,
-		username,
-		userRepoName,
-		tagName,
-		fmt.Sprintf("%s %s", manifest.Name, manifest.Version),
-		manifest.Description,
-	)
+// buildPlugin bundles the plugin for production, with the package.json build
+// script when the project opts out of the built-in bundler. pm overrides the
+// detected package manager.
+func buildPlugin(dir string, pm string) error {
+	project, err := config.LoadProject(dir)
 	if err != nil {
-		return "", fmt.Errorf("failed to create release: %v", err)
+		return err
 	}
-
-	assets := []string{"main.js", "manifest.json"}
-
-	if _, err := os.Stat(filepath.Join(*dir, "styles.css")); err == nil {
-		assets = append(assets, "styles.css")
+	if project.Build.Script {
+		return build.Script(dir, pm)
 	}
 
-	for _, asset := range assets {
-		assetPath := filepath.Join(*dir, asset)
-		utils.Info("Uploading %s...", asset)
-
-		contentType := "application/javascript"
-		if strings.HasSuffix(asset, ".json") {
-			contentType = "application/json"
-		} else if strings.HasSuffix(asset, ".css") {
-			contentType = "text/css"
-		}
-
-		if err := github.UploadReleaseAsset(token, newRelease.UploadURL, assetPath, contentType); err != nil {
-			return "", fmt.Errorf("failed to upload %s: %v", asset, err)
+	if _, err := build.EntryPoint(dir); err != nil {
+		if _, statErr := os.Stat(filepath.Join(dir, build.Outfile)); statErr == nil {
+			utils.Warn("%v. Skipping build step (using the existing %s).", err, build.Outfile)
+			return nil
 		}
+		return err
 	}
 
-	utils.Success("Release published successfully!")
-
-	utils.Info("Proceeding to update Community Registry...")
-
-	if _, err := github.ForkRepo(token); err != nil {
-		return "", err
-	}
-
-	branch := fmt.Sprintf("add-plugin/%s", userRepoName)
-	communityRepo := fmt.Sprintf("%s/inkdown-community", username)
-
-	sha, err := github.GetBranchSHA(token, communityRepo)
-	if err != nil {
-		return "", err
+	if build.NeedsInstall(dir) {
+		if err := build.Install(dir, pm); err != nil {
+			return err
+		}
 	}
+	return build.Bundle(dir, build.Options{Production: true})
+}
 
-	_ = github.CreateBranch(token, communityRepo, branch, sha)
+func pluginAssets(dir string) []string {
+	assets := []string{"main.js", "manifest.json"}
 
-	content, contentSha, err := github.GetFileContent(token, communityRepo, branch, "plugins.json")
-	if err != nil {
-		return "", err
+	if _, err := os.Stat(filepath.Join(dir, "styles.css")); err == nil {
+		assets = append(assets, "styles.css")
 	}
 
-	pluginEntry := fmt.Sprintf(`{
-  "id": "%s",
-  "name": "%s",
-  "author": "%s",
-  "version": "%s",
-  "description": "%s",
-  "repo": "%s/%s"
-}`,
-		userRepoName,
-		manifest.Name,
-		username,
-		manifest.Version,
-		manifest.Description,
-		username,
-		userRepoName,
-	)
-
-	utils.Info("Updating plugins.json...")
-	updated := github.AppendPlugin(content, pluginEntry)
-
-	if err := github.UpdateFile(
-		token,
-		communityRepo,
-		branch,
-		"plugins.json",
-		updated,
-		contentSha,
-		fmt.Sprintf("feat: add plugin %s v%s", manifest.Name, manifest.Version),
-	); err != nil {
-		return "", err
-	}
+	return assets
+}
 
-	prBody := fmt.Sprintf(
+func pluginPRBody(manifest *Package) string {
+	return fmt.Sprintf(
 		"\n### New Plugin (v%s)\n\n"+
 			"- **Name:** %s\n"+
 			"- **Description:** %s\n\n"+
@@ -309,17 +204,4 @@ func PublishPlugin(dir *string) (string, error) {
 		manifest.Name,
 		manifest.Description,
 	)
-
-	utils.Note("Creating the following PR:\n%s", prBody)
-	reader := bufio.NewReader(os.Stdin)
-	utils.Prompt("Please provide a title for your PR: ")
-	title, _ := reader.ReadString('\n')
-	title = strings.TrimSpace(title)
-
-	prURL, err := github.CreatePR(token, username+":"+branch, title, prBody)
-	if err != nil {
-		return "", err
-	}
-
-	return prURL, nil
 }
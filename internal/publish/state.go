@@ -0,0 +1,103 @@
+package publish
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/adrg/xdg"
+)
+
+// State is the checkpoint of a publish run for one project version. It is
+// saved after every completed step so an interrupted run can be resumed.
+type State struct {
+	Kind    string `json:"kind"`
+	Repo    string `json:"repo"`
+	Version string `json:"version"`
+
+	Completed []string `json:"completed"`
+
+	ReleaseID      int      `json:"release_id,omitempty"`
+	ReleaseURL     string   `json:"release_url,omitempty"`
+	UploadedAssets []string `json:"uploaded_assets,omitempty"`
+	Fork           string   `json:"fork,omitempty"`
+	Branch         string   `json:"branch,omitempty"`
+	PRURL          string   `json:"pr_url,omitempty"`
+
+	UpdatedAt time.Time `json:"updated_at"`
+
+	path string
+}
+
+func statePath(kind, repo, version string) string {
+	name := fmt.Sprintf("%s-%s-%s.json", kind, strings.ReplaceAll(repo, "/", "_"), version)
+	return filepath.Join(xdg.StateHome, "ink", "publish", name)
+}
+
+// LoadState reads the checkpoint for a project version. It returns a fresh
+// state when none has been saved.
+func LoadState(kind, repo, version string) (*State, error) {
+	path := statePath(kind, repo, version)
+	state := &State{Kind: kind, Repo: repo, Version: version, path: path}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("corrupt publish state %s: %v", path, err)
+	}
+	state.path = path
+
+	return state, nil
+}
+
+func (s *State) Save() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return err
+	}
+
+	s.UpdatedAt = time.Now().UTC()
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(s.path, data, 0600)
+}
+
+// Reset discards every checkpoint so the next run starts from scratch.
+func (s *State) Reset() error {
+	*s = State{Kind: s.Kind, Repo: s.Repo, Version: s.Version, path: s.path}
+	if err := os.Remove(s.path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (s *State) Started() bool {
+	return len(s.Completed) > 0
+}
+
+func (s *State) Done(step string) bool {
+	return slices.Contains(s.Completed, step)
+}
+
+func (s *State) complete(step string) error {
+	if !s.Done(step) {
+		s.Completed = append(s.Completed, step)
+	}
+	return s.Save()
+}
+
+func (s *State) Path() string {
+	return s.path
+}
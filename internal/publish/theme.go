@@ -0,0 +1,91 @@
+package publish
+
+import (
+	"fmt"
+	"strings"
+
+	"inkdown-cli/internal/registry"
+	"inkdown-cli/internal/validate"
+	"inkdown-cli/utils"
+)
+
+func PublishTheme(dir *string, opts Options) (*Result, error) {
+	if err := opts.check(); err != nil {
+		return nil, err
+	}
+
+	utils.Info("Started the publish process...")
+
+	if _, err := validate.ValidateTheme(*dir, validate.ThemeOptions{}); err != nil {
+		return nil, err
+	}
+
+	manifest, err := validate.LoadThemeManifest(*dir)
+	if err != nil {
+		return nil, fmt.Errorf("%v (make sure you are in the theme root)", err)
+	}
+
+	utils.Info("Detected Theme: %s v%s", manifest.Name, manifest.Version)
+
+	client, err := authenticate()
+	if err != nil {
+		return nil, err
+	}
+
+	username, userRepoName, err := detectRepo(client, *dir, manifest.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	// theme.json has no id, so themes are registered under their repository
+	// name; Upsert refuses a name another repository already holds.
+	entry := registry.Entry{
+		ID:          userRepoName,
+		Name:        manifest.Name,
+		Author:      username,
+		Version:     manifest.Version,
+		Description: manifest.Description,
+		Modes:       manifest.CSSModes(),
+		Repo:        username + "/" + userRepoName,
+	}
+
+	return publishArtifact(client, &artifact{
+		Kind:         "theme",
+		Dir:          *dir,
+		Name:         manifest.Name,
+		Version:      manifest.Version,
+		Description:  manifest.Description,
+		Assets:       themeAssets(manifest),
+		Owner:        username,
+		Repo:         userRepoName,
+		RegistryFile: "themes.json",
+		UpdateRegistry: func(existing string) (string, error) {
+			return upsertRegistry(existing, entry)
+		},
+		CommitMessage: fmt.Sprintf("feat: add theme %s v%s", manifest.Name, manifest.Version),
+		PRTitle:       fmt.Sprintf("Add theme %s v%s", manifest.Name, manifest.Version),
+		PRBody:        themePRBody(manifest),
+	}, opts)
+}
+
+func themeAssets(manifest *validate.ThemeManifest) []string {
+	assets := []string{"theme.json"}
+	for _, mode := range manifest.CSSModes() {
+		assets = append(assets, mode+".css")
+	}
+	return assets
+}
+
+func themePRBody(manifest *validate.ThemeManifest) string {
+	return fmt.Sprintf(
+		"\n### New Theme (v%s)\n\n"+
+			"- **Name:** %s\n"+
+			"- **Description:** %s\n"+
+			"- **Modes:** %s\n\n"+
+			"Published via Inkdown CLI.",
+		manifest.Version,
+		manifest.Name,
+		manifest.Description,
+		strings.Join(manifest.CSSModes(), ", "),
+	)
+}
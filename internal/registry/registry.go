@@ -0,0 +1,408 @@
+package registry
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Entry is a plugin or theme record in an inkdown-community registry file
+// (plugins.json or themes.json).
+type Entry struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Author      string   `json:"author"`
+	Version     string   `json:"version"`
+	Description string   `json:"description"`
+	Modes       []string `json:"modes,omitempty"`
+	Repo        string   `json:"repo"`
+}
+
+// Registry is a parsed registry file. It keeps the original source so that
+// entries which are not touched are written back byte for byte.
+type Registry struct {
+	Entries []Entry
+
+	src   string
+	items []item
+}
+
+// item tracks where an entry came from and whether it must be re-rendered.
+type item struct {
+	start, end int // byte span in src; -1 for appended entries
+	dirty      bool
+}
+
+// Parse parses a registry file. An empty file is treated as an empty list.
+func Parse(content string) (*Registry, error) {
+	r := &Registry{src: content}
+	if strings.TrimSpace(content) == "" {
+		r.src = "[]\n"
+		return r, nil
+	}
+
+	dec := json.NewDecoder(strings.NewReader(content))
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, fmt.Errorf("invalid registry: %v", err)
+	}
+	if d, ok := tok.(json.Delim); !ok || d != '[' {
+		return nil, fmt.Errorf("invalid registry: expected a JSON array")
+	}
+
+	prevEnd := int(dec.InputOffset())
+	for dec.More() {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return nil, fmt.Errorf("invalid registry: %v", err)
+		}
+		end := int(dec.InputOffset())
+		start := prevEnd
+		for start < end && strings.IndexByte(" \t\r\n,", content[start]) >= 0 {
+			start++
+		}
+
+		var e Entry
+		if err := json.Unmarshal(raw, &e); err != nil {
+			return nil, fmt.Errorf("invalid registry entry at offset %d: %v", start, err)
+		}
+
+		r.Entries = append(r.Entries, e)
+		r.items = append(r.items, item{start: start, end: end})
+		prevEnd = end
+	}
+
+	if _, err := dec.Token(); err != nil {
+		return nil, fmt.Errorf("invalid registry: %v", err)
+	}
+	if _, err := dec.Token(); err == nil {
+		return nil, fmt.Errorf("invalid registry: unexpected data after the array")
+	}
+
+	return r, nil
+}
+
+// Find returns the index of the entry with the given id, or -1.
+func (r *Registry) Find(id string) int {
+	for i, e := range r.Entries {
+		if e.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// Upsert updates the entry with the same id in place, or appends it when it
+// is new. An entry published from the same repository under another id is
+// updated too, taking the new id. It reports whether the entry was added,
+// and refuses to replace an entry that belongs to another repository or
+// author.
+func (r *Registry) Upsert(e Entry) (bool, error) {
+	if len(e.Modes) == 0 {
+		e.Modes = nil
+	}
+
+	i := r.Find(e.ID)
+	if i < 0 {
+		i = r.findRepo(e.Repo)
+	}
+	if i >= 0 {
+		existing := r.Entries[i]
+		if !strings.EqualFold(existing.Repo, e.Repo) || !strings.EqualFold(existing.Author, e.Author) {
+			return false, fmt.Errorf("registry id %q already belongs to %s by %s; use a different id", e.ID, existing.Repo, existing.Author)
+		}
+		if !reflect.DeepEqual(existing, e) {
+			r.Entries[i] = e
+			r.items[i].dirty = true
+		}
+		return false, nil
+	}
+
+	r.Entries = append(r.Entries, e)
+	r.items = append(r.items, item{start: -1, end: -1, dirty: true})
+	return true, nil
+}
+
+// findRepo returns the index of the entry published from repo, or -1.
+func (r *Registry) findRepo(repo string) int {
+	for i, e := range r.Entries {
+		if strings.EqualFold(e.Repo, repo) {
+			return i
+		}
+	}
+	return -1
+}
+
+// String renders the registry. Unchanged entries keep their original text;
+// updated entries keep their key order and unknown fields. The result is
+// re-parsed and an error is returned if it does not round-trip.
+func (r *Registry) String() (string, error) {
+	indent, unit := r.indentation()
+
+	var out strings.Builder
+	if len(r.items) == 0 || r.items[0].start < 0 {
+		// No existing entries: rebuild the array around the new ones.
+		out.WriteString("[")
+		for i := range r.items {
+			if i > 0 {
+				out.WriteString(",")
+			}
+			out.WriteString("\n" + indent)
+			s, err := r.render(i, indent, unit)
+			if err != nil {
+				return "", err
+			}
+			out.WriteString(s)
+		}
+		if len(r.items) > 0 {
+			out.WriteString("\n")
+		}
+		out.WriteString("]\n")
+	} else {
+		out.WriteString(r.src[:r.items[0].start])
+		last := 0
+		for i, it := range r.items {
+			if it.start < 0 {
+				out.WriteString(r.separator())
+			} else if i > 0 {
+				out.WriteString(r.src[r.items[i-1].end:it.start])
+			}
+
+			s, err := r.render(i, indent, unit)
+			if err != nil {
+				return "", err
+			}
+			out.WriteString(s)
+
+			if it.start >= 0 {
+				last = i
+			}
+		}
+		out.WriteString(r.src[r.items[last].end:])
+	}
+
+	result := out.String()
+
+	check, err := Parse(result)
+	if err != nil {
+		return "", fmt.Errorf("refusing to write registry: result does not parse: %v", err)
+	}
+	if len(check.Entries) != len(r.Entries) || (len(r.Entries) > 0 && !reflect.DeepEqual(check.Entries, r.Entries)) {
+		return "", fmt.Errorf("refusing to write registry: result does not match the expected entries")
+	}
+
+	return result, nil
+}
+
+func (r *Registry) render(i int, indent, unit string) (string, error) {
+	it := r.items[i]
+	if !it.dirty {
+		return r.src[it.start:it.end], nil
+	}
+
+	fields, err := fieldsOf(r.Entries[i])
+	if err != nil {
+		return "", err
+	}
+
+	if it.start >= 0 {
+		// Keep the existing key order and any fields this CLI does not know.
+		existing, err := decodeObject([]byte(r.src[it.start:it.end]))
+		if err != nil {
+			return "", err
+		}
+		fields = merge(existing, fields)
+	}
+
+	return renderObject(fields, lineIndent(r.src, it.start, indent), unit)
+}
+
+// separator returns the text placed before an appended entry, reusing the
+// separator between the existing entries when there is one.
+func (r *Registry) separator() string {
+	for i := 1; i < len(r.items); i++ {
+		if r.items[i].start >= 0 && r.items[i-1].start >= 0 {
+			return r.src[r.items[i-1].end:r.items[i].start]
+		}
+	}
+	indent, _ := r.indentation()
+	return ",\n" + indent
+}
+
+// indentation detects the indent of top-level entries and the indent unit
+// used inside them, defaulting to two spaces.
+func (r *Registry) indentation() (string, string) {
+	indent, unit := "  ", "  "
+	for _, it := range r.items {
+		if it.start < 0 {
+			continue
+		}
+		indent = lineIndent(r.src, it.start, indent)
+
+		body := r.src[it.start:it.end]
+		if nl := strings.IndexByte(body, '\n'); nl >= 0 {
+			rest := body[nl+1:]
+			inner := rest[:len(rest)-len(strings.TrimLeft(rest, " \t"))]
+			if strings.HasPrefix(inner, indent) && len(inner) > len(indent) {
+				unit = inner[len(indent):]
+			}
+		}
+		break
+	}
+	return indent, unit
+}
+
+// lineIndent returns the whitespace preceding offset on its line, or def if
+// offset is not at the start of its line.
+func lineIndent(src string, offset int, def string) string {
+	if offset < 0 {
+		return def
+	}
+	lineStart := strings.LastIndexByte(src[:offset], '\n') + 1
+	prefix := src[lineStart:offset]
+	if strings.TrimLeft(prefix, " \t") != "" {
+		return def
+	}
+	return prefix
+}
+
+type field struct {
+	Key   string
+	Value json.RawMessage
+}
+
+func fieldsOf(e Entry) ([]field, error) {
+	raw, err := marshal(e)
+	if err != nil {
+		return nil, err
+	}
+	return decodeObject(raw)
+}
+
+// decodeObject decodes a JSON object into its fields, preserving key order.
+func decodeObject(data []byte) ([]field, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+
+	var fields []field
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, ok := tok.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected an object key")
+		}
+
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return nil, err
+		}
+		fields = append(fields, field{Key: key, Value: value})
+	}
+
+	return fields, nil
+}
+
+// merge overlays updated onto existing: known keys keep their position,
+// keys dropped from the entry (e.g. empty modes) are removed, new keys are
+// appended and unknown keys are left alone.
+func merge(existing, updated []field) []field {
+	known := map[string]bool{}
+	for _, key := range []string{"id", "name", "author", "version", "description", "modes", "repo"} {
+		known[key] = true
+	}
+	values := map[string]json.RawMessage{}
+	for _, f := range updated {
+		values[f.Key] = f.Value
+	}
+
+	var out []field
+	seen := map[string]bool{}
+	for _, f := range existing {
+		if v, ok := values[f.Key]; ok {
+			out = append(out, field{f.Key, v})
+			seen[f.Key] = true
+		} else if !known[f.Key] {
+			out = append(out, f)
+		}
+	}
+	for _, f := range updated {
+		if !seen[f.Key] {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+func renderObject(fields []field, indent, unit string) (string, error) {
+	var sb strings.Builder
+	sb.WriteString("{")
+	for i, f := range fields {
+		if i > 0 {
+			sb.WriteString(",")
+		}
+
+		key, err := marshal(f.Key)
+		if err != nil {
+			return "", err
+		}
+
+		value, err := renderValue(f.Value, indent+unit, unit)
+		if err != nil {
+			return "", err
+		}
+
+		sb.WriteString("\n" + indent + unit)
+		sb.Write(key)
+		sb.WriteString(": ")
+		sb.WriteString(value)
+	}
+	sb.WriteString("\n" + indent + "}")
+	return sb.String(), nil
+}
+
+// renderValue indents a field value. Arrays of scalars such as "modes" stay
+// on one line.
+func renderValue(value json.RawMessage, indent, unit string) (string, error) {
+	var items []json.RawMessage
+	if err := json.Unmarshal(value, &items); err == nil {
+		parts := make([]string, 0, len(items))
+		for _, item := range items {
+			var compact bytes.Buffer
+			if err := json.Compact(&compact, item); err != nil {
+				return "", err
+			}
+			if c := compact.Bytes(); len(c) > 0 && (c[0] == '{' || c[0] == '[') {
+				parts = nil
+				break
+			}
+			parts = append(parts, compact.String())
+		}
+		if parts != nil || len(items) == 0 {
+			return "[" + strings.Join(parts, ", ") + "]", nil
+		}
+	}
+
+	var out bytes.Buffer
+	if err := json.Indent(&out, value, indent, unit); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+// marshal encodes v without HTML escaping so descriptions stay readable.
+func marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(buf.Bytes(), "\n"), nil
+}
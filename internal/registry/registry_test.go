@@ -0,0 +1,115 @@
+package registry
+
+import (
+	"strings"
+	"testing"
+)
+
+const tabRegistry = "[\n\t{\n\t\t\"id\": \"a\",\n\t\t\"name\": \"A\",\n\t\t\"author\": \"x\",\n\t\t\"version\": \"1.0.0\",\n\t\t\"description\": \"First\",\n\t\t\"repo\": \"x/a\",\n\t\t\"stars\": 3\n\t},\n\t{\"id\": \"b\", \"name\": \"B\", \"author\": \"y\", \"version\": \"2.0.0\", \"description\": \"<b>\", \"repo\": \"y/b\"}\n]\n"
+
+func upsert(t *testing.T, content string, e Entry) string {
+	t.Helper()
+	reg, err := Parse(content)
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	if _, err := reg.Upsert(e); err != nil {
+		t.Fatalf("Upsert error: %v", err)
+	}
+	out, err := reg.String()
+	if err != nil {
+		t.Fatalf("String error: %v", err)
+	}
+	return out
+}
+
+func TestUpsertUnchanged(t *testing.T) {
+	reg, err := Parse(tabRegistry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if added, err := reg.Upsert(reg.Entries[1]); added || err != nil {
+		t.Errorf("Upsert of an existing entry = %v, %v, want false, nil", added, err)
+	}
+	out, err := reg.String()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != tabRegistry {
+		t.Errorf("unchanged registry was rewritten:\n%s", out)
+	}
+}
+
+func TestUpsertUpdatesInPlace(t *testing.T) {
+	got := upsert(t, tabRegistry, Entry{ID: "a", Name: "A", Author: "x", Version: "1.1.0", Description: "First & best", Repo: "x/a"})
+
+	want := "[\n\t{\n\t\t\"id\": \"a\",\n\t\t\"name\": \"A\",\n\t\t\"author\": \"x\",\n\t\t\"version\": \"1.1.0\",\n\t\t\"description\": \"First & best\",\n\t\t\"repo\": \"x/a\",\n\t\t\"stars\": 3\n\t},\n\t{\"id\": \"b\", \"name\": \"B\", \"author\": \"y\", \"version\": \"2.0.0\", \"description\": \"<b>\", \"repo\": \"y/b\"}\n]\n"
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestUpsertAppends(t *testing.T) {
+	got := upsert(t, tabRegistry, Entry{ID: "c", Name: "C", Author: "z", Version: "0.1.0", Description: "Dark", Modes: []string{"dark", "light"}, Repo: "z/c"})
+
+	want := strings.TrimSuffix(tabRegistry, "\n]\n") + ",\n\t{\n\t\t\"id\": \"c\",\n\t\t\"name\": \"C\",\n\t\t\"author\": \"z\",\n\t\t\"version\": \"0.1.0\",\n\t\t\"description\": \"Dark\",\n\t\t\"modes\": [\"dark\", \"light\"],\n\t\t\"repo\": \"z/c\"\n\t}\n]\n"
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestUpsertRenamedID(t *testing.T) {
+	got := upsert(t, tabRegistry, Entry{ID: "b-plugin", Name: "B", Author: "y", Version: "2.1.0", Description: "<b>", Repo: "Y/b"})
+
+	reg, err := Parse(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reg.Entries) != 2 || reg.Entries[1].ID != "b-plugin" || reg.Entries[1].Version != "2.1.0" {
+		t.Errorf("entry published from the same repository was not updated in place:\n%s", got)
+	}
+}
+
+func TestUpsertRefusesOtherRepository(t *testing.T) {
+	tests := []struct {
+		name  string
+		entry Entry
+	}{
+		{"same id, other repository", Entry{ID: "a", Name: "A", Author: "mallory", Version: "9.0.0", Description: "Mine", Repo: "mallory/a"}},
+		{"same id and repository, other author", Entry{ID: "a", Name: "A", Author: "mallory", Version: "9.0.0", Description: "Mine", Repo: "x/a"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reg, err := Parse(tabRegistry)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := reg.Upsert(tt.entry); err == nil {
+				t.Fatal("Upsert replaced an entry of another repository")
+			}
+			if out, err := reg.String(); err != nil || out != tabRegistry {
+				t.Errorf("registry changed after a refused upsert:\n%s", out)
+			}
+		})
+	}
+}
+
+func TestUpsertEmptyRegistry(t *testing.T) {
+	for _, content := range []string{"", "[]", "[ ]\n"} {
+		got := upsert(t, content, Entry{ID: "a", Name: "A", Author: "x", Version: "1.0.0", Description: "d", Repo: "x/a"})
+
+		want := "[\n  {\n    \"id\": \"a\",\n    \"name\": \"A\",\n    \"author\": \"x\",\n    \"version\": \"1.0.0\",\n    \"description\": \"d\",\n    \"repo\": \"x/a\"\n  }\n]\n"
+		if got != want {
+			t.Errorf("upsert into %q:\ngot:\n%s\nwant:\n%s", content, got, want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, content := range []string{"{}", "[1]", "[{\"id\": \"a\"}", "[] []"} {
+		if _, err := Parse(content); err == nil {
+			t.Errorf("Parse(%q) succeeded, want an error", content)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package reporter
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"inkdown-cli/internal/validate"
+	"inkdown-cli/utils"
+
+	"github.com/spf13/cobra"
+)
+
+// ValidateCommand holds the flags of `ink plugin validate` and `ink theme
+// validate` and runs both the same way.
+type ValidateCommand struct {
+	Path       string
+	Format     string
+	OutputFile string
+}
+
+// AddFlags registers --path, --format and --output-file on cmd. kind names
+// the project in the help text.
+func (c *ValidateCommand) AddFlags(cmd *cobra.Command, kind string) {
+	cmd.Flags().StringVarP(&c.Path, "path", "p", ".", "Path to the "+kind)
+	cmd.Flags().StringVar(&c.Format, "format", FormatText, "Report format: text, json, sarif or junit")
+	cmd.Flags().StringVar(&c.OutputFile, "output-file", "", "Write the report to a file instead of stdout")
+}
+
+// Run validates the project directory with run and writes the report as the
+// flags and --output ask. The error is non-nil when validation fails.
+func (c *ValidateCommand) Run(run func(dir string) (*validate.Report, error)) error {
+	path := c.Path
+	if path == "" {
+		path = "."
+	}
+
+	if err := Check(c.Format, c.OutputFile); err != nil {
+		return err
+	}
+	report := c.Format != "" && c.Format != FormatText
+	// A report on stdout would be mixed with the console output.
+	if report && c.OutputFile == "" {
+		if utils.JSONOutput() {
+			return fmt.Errorf("--format %s writes to stdout; use --output-file with --output json", c.Format)
+		}
+		utils.SetLogWriter(os.Stderr)
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(abs)
+	if err != nil {
+		return fmt.Errorf("path not found: %s", abs)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("path must be a directory: %s", abs)
+	}
+
+	result, err := run(abs)
+	if utils.JSONOutput() && result != nil {
+		if jsonErr := utils.PrintJSON(result); jsonErr != nil {
+			return jsonErr
+		}
+	}
+	if report && result != nil {
+		if reportErr := WriteFile(c.OutputFile, c.Format, result); reportErr != nil {
+			return reportErr
+		}
+	}
+	// The findings are already printed by the validator.
+	return err
+}
@@ -0,0 +1,131 @@
+package reporter
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+
+	"inkdown-cli/internal/validate"
+)
+
+// JUnit XML as read by common CI test dashboards: one suite per file, one
+// test case per finding. Errors are failures, warnings pass with their
+// message as output and suppressed findings are skipped.
+type junitSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Name     string       `xml:"name,attr"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Skipped  int          `xml:"skipped,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Errors   int         `xml:"errors,attr"`
+	Skipped  int         `xml:"skipped,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+func writeJUnit(w io.Writer, report *validate.Report) error {
+	root := junitSuites{Name: fmt.Sprintf("ink %s validate", report.Kind)}
+
+	suites := map[string]int{}
+	suiteFor := func(file string) *junitSuite {
+		if file == "" {
+			file = report.Kind
+		}
+		index, ok := suites[file]
+		if !ok {
+			index = len(root.Suites)
+			suites[file] = index
+			root.Suites = append(root.Suites, junitSuite{Name: file})
+		}
+		return &root.Suites[index]
+	}
+
+	addCase := func(f validate.Finding, suppressed bool) {
+		suite := suiteFor(f.File)
+		c := junitCase{Name: caseName(f), ClassName: ruleID(f)}
+
+		switch {
+		case suppressed:
+			c.Skipped = &junitSkipped{Message: "suppressed by ink-ignore-next-line"}
+			suite.Skipped++
+			root.Skipped++
+		case f.Severity == validate.SeverityWarning:
+			c.SystemOut = f.Message
+		default:
+			text := f.Message
+			if f.Hint != "" {
+				text += "\n" + f.Hint
+			}
+			if f.Docs != "" {
+				text += "\n" + f.Docs
+			}
+			c.Failure = &junitFailure{Message: f.Message, Type: ruleID(f), Text: text}
+			suite.Failures++
+			root.Failures++
+		}
+
+		suite.Tests++
+		root.Tests++
+		suite.Cases = append(suite.Cases, c)
+	}
+
+	for _, f := range report.Findings {
+		addCase(f, false)
+	}
+	for _, f := range report.Suppressed {
+		addCase(f, true)
+	}
+
+	// A clean run still reports one passing case so dashboards show it.
+	if root.Tests == 0 {
+		suite := suiteFor("")
+		suite.Cases = append(suite.Cases, junitCase{Name: "validate", ClassName: report.Kind})
+		suite.Tests++
+		root.Tests++
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(root); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func caseName(f validate.Finding) string {
+	switch {
+	case f.File == "":
+		return ruleID(f)
+	case f.Line > 0:
+		return fmt.Sprintf("%s %s:%d:%d", ruleID(f), f.File, f.Line, f.Column)
+	}
+	return fmt.Sprintf("%s %s", ruleID(f), f.File)
+}
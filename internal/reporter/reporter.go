@@ -0,0 +1,69 @@
+// Package reporter writes validation reports in formats that CI systems and
+// code-scanning tools understand.
+package reporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"inkdown-cli/internal/validate"
+)
+
+const (
+	FormatText  = "text"
+	FormatJSON  = "json"
+	FormatSARIF = "sarif"
+	FormatJUnit = "junit"
+)
+
+// Check validates a --format / --output-file combination before anything is
+// run. Text is the regular console output and cannot be written to a file.
+func Check(format, outputFile string) error {
+	switch format {
+	case "", FormatText:
+		if outputFile != "" {
+			return fmt.Errorf("--output-file requires --format json, sarif or junit")
+		}
+	case FormatJSON, FormatSARIF, FormatJUnit:
+	default:
+		return fmt.Errorf("invalid --format %q: must be text, json, sarif or junit", format)
+	}
+	return nil
+}
+
+// Write renders the report in format to w.
+func Write(w io.Writer, format string, report *validate.Report) error {
+	switch format {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		enc.SetEscapeHTML(false)
+		return enc.Encode(report)
+	case FormatSARIF:
+		return writeSARIF(w, report)
+	case FormatJUnit:
+		return writeJUnit(w, report)
+	}
+	return fmt.Errorf("unsupported report format %q", format)
+}
+
+// WriteFile renders the report in format to path, or to stdout when path is
+// empty.
+func WriteFile(path, format string, report *validate.Report) error {
+	if path == "" {
+		return Write(os.Stdout, format, report)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("could not create report file: %v", err)
+	}
+
+	if err := Write(f, format, report); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
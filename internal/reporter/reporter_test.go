@@ -0,0 +1,196 @@
+package reporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"inkdown-cli/internal/validate"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// testReport has an error and a warning with a location, an error with a
+// file but no line, an error with no location and a suppressed finding.
+func testReport() *validate.Report {
+	return &validate.Report{
+		Kind: "plugin",
+		Dir:  "/work/my-plugin",
+		Findings: []validate.Finding{
+			{
+				Severity: validate.SeverityError,
+				Message:  `src/main.ts:3:5: Direct access to "window"`,
+				Hint:     `Direct access to "window" is forbidden.`,
+				File:     "src/main.ts",
+				Line:     3,
+				Column:   5,
+				Rule:     "no-window",
+				Docs:     "https://example.com/rules#no-window",
+			},
+			{
+				Severity: validate.SeverityWarning,
+				Message:  "dark.css: low contrast",
+				File:     "dark.css",
+				Line:     2,
+				Column:   3,
+				Rule:     "contrast",
+			},
+			{
+				Severity: validate.SeverityError,
+				Message:  "manifest.json missing 'id'",
+				File:     "manifest.json",
+			},
+			{
+				Severity: validate.SeverityError,
+				Message:  ".inkrc.json: unknown rule \"x\"",
+				Rule:     "project-config",
+			},
+		},
+		Suppressed: []validate.Finding{
+			{
+				Severity: validate.SeverityError,
+				Message:  `src/main.ts:7:1: Direct access to "document"`,
+				File:     "src/main.ts",
+				Line:     7,
+				Column:   1,
+				Rule:     "no-document",
+			},
+		},
+	}
+}
+
+// render writes report in format and compares it with testdata/golden.
+func render(t *testing.T, format, golden string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := Write(&buf, format, testReport()); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join("testdata", golden)
+	if *update {
+		if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Errorf("%s output differs from %s (run go test -update to accept):\n%s", format, path, buf.Bytes())
+	}
+	return buf.Bytes()
+}
+
+func TestSARIF(t *testing.T) {
+	out := render(t, FormatSARIF, "report.sarif")
+
+	var log struct {
+		Runs []struct {
+			Results []struct {
+				RuleID    string `json:"ruleId"`
+				Level     string `json:"level"`
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct {
+							URI string `json:"uri"`
+						} `json:"artifactLocation"`
+						Region *struct {
+							StartLine   int `json:"startLine"`
+							StartColumn int `json:"startColumn"`
+						} `json:"region"`
+					} `json:"physicalLocation"`
+				} `json:"locations"`
+				Suppressions []struct {
+					Kind string `json:"kind"`
+				} `json:"suppressions"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal(out, &log); err != nil {
+		t.Fatal(err)
+	}
+	results := log.Runs[0].Results
+
+	want := []struct {
+		ruleID, level string
+		locations     int
+		region        bool
+		suppressed    bool
+	}{
+		{"no-window", "error", 1, true, false},
+		{"contrast", "warning", 1, true, false},
+		{"ink", "error", 1, false, false},
+		{"project-config", "error", 0, false, false},
+		{"no-document", "error", 1, true, true},
+	}
+	if len(results) != len(want) {
+		t.Fatalf("got %d results, want %d", len(results), len(want))
+	}
+	for i, w := range want {
+		r := results[i]
+		if r.RuleID != w.ruleID || r.Level != w.level {
+			t.Errorf("result %d = %s %s, want %s %s", i, r.RuleID, r.Level, w.ruleID, w.level)
+		}
+		if len(r.Locations) != w.locations {
+			t.Errorf("result %d has %d locations, want %d", i, len(r.Locations), w.locations)
+			continue
+		}
+		if w.locations > 0 && (r.Locations[0].PhysicalLocation.Region != nil) != w.region {
+			t.Errorf("result %d region = %v, want present: %v", i, r.Locations[0].PhysicalLocation.Region, w.region)
+		}
+		if (len(r.Suppressions) > 0) != w.suppressed {
+			t.Errorf("result %d suppressions = %v, want suppressed: %v", i, r.Suppressions, w.suppressed)
+		}
+	}
+}
+
+func TestJUnit(t *testing.T) {
+	out := render(t, FormatJUnit, "report.junit.xml")
+
+	var suites junitSuites
+	if err := xml.Unmarshal(out, &suites); err != nil {
+		t.Fatal(err)
+	}
+	if suites.Tests != 5 || suites.Failures != 3 || suites.Skipped != 1 {
+		t.Errorf("totals: tests=%d failures=%d skipped=%d, want 5, 3 and 1", suites.Tests, suites.Failures, suites.Skipped)
+	}
+
+	type counts struct{ tests, failures, skipped, cases int }
+	want := map[string]counts{
+		"src/main.ts":   {2, 1, 1, 2},
+		"dark.css":      {1, 0, 0, 1},
+		"manifest.json": {1, 1, 0, 1},
+		"plugin":        {1, 1, 0, 1},
+	}
+	if len(suites.Suites) != len(want) {
+		t.Errorf("got %d suites, want %d", len(suites.Suites), len(want))
+	}
+	for _, s := range suites.Suites {
+		got := counts{s.Tests, s.Failures, s.Skipped, len(s.Cases)}
+		if got != want[s.Name] {
+			t.Errorf("suite %s = %+v, want %+v", s.Name, got, want[s.Name])
+		}
+	}
+}
+
+func TestJUnitCleanRun(t *testing.T) {
+	var buf bytes.Buffer
+	report := &validate.Report{Kind: "theme", Dir: "/work/t", Valid: true}
+	if err := Write(&buf, FormatJUnit, report); err != nil {
+		t.Fatal(err)
+	}
+
+	var suites junitSuites
+	if err := xml.Unmarshal(buf.Bytes(), &suites); err != nil {
+		t.Fatal(err)
+	}
+	if suites.Tests != 1 || suites.Failures != 0 || len(suites.Suites) != 1 || suites.Suites[0].Cases[0].Name != "validate" {
+		t.Errorf("clean run = %+v, want one passing validate case", suites)
+	}
+}
@@ -0,0 +1,169 @@
+package reporter
+
+import (
+	"encoding/json"
+	"io"
+	"net/url"
+	"path/filepath"
+
+	"inkdown-cli/internal/validate"
+)
+
+// SARIF 2.1.0, the subset understood by GitHub code scanning.
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool               sarifTool                        `json:"tool"`
+	OriginalURIBaseIDs map[string]sarifArtifactLocation `json:"originalUriBaseIds,omitempty"`
+	Results            []sarifResult                    `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+	HelpURI          string       `json:"helpUri,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID       string             `json:"ruleId"`
+	RuleIndex    int                `json:"ruleIndex"`
+	Level        string             `json:"level"`
+	Message      sarifMessage       `json:"message"`
+	Locations    []sarifLocation    `json:"locations,omitempty"`
+	Suppressions []sarifSuppression `json:"suppressions,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+type sarifSuppression struct {
+	Kind string `json:"kind"`
+}
+
+const srcRoot = "%SRCROOT%"
+
+func writeSARIF(w io.Writer, report *validate.Report) error {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "ink",
+			InformationURI: "https://github.com/inkdown/inkdown-cli",
+			Rules:          []sarifRule{},
+		}},
+		OriginalURIBaseIDs: map[string]sarifArtifactLocation{
+			srcRoot: {URI: dirURI(report.Dir)},
+		},
+		Results: []sarifResult{},
+	}
+
+	ruleIndex := map[string]int{}
+	addResult := func(f validate.Finding, suppressed bool) {
+		id := ruleID(f)
+		index, ok := ruleIndex[id]
+		if !ok {
+			index = len(run.Tool.Driver.Rules)
+			ruleIndex[id] = index
+
+			description := f.Hint
+			if description == "" {
+				description = id
+			}
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{
+				ID:               id,
+				ShortDescription: sarifMessage{Text: description},
+				HelpURI:          f.Docs,
+			})
+		}
+
+		result := sarifResult{
+			RuleID:    id,
+			RuleIndex: index,
+			Level:     "error",
+			Message:   sarifMessage{Text: f.Message},
+		}
+		if f.Severity == validate.SeverityWarning {
+			result.Level = "warning"
+		}
+		if f.File != "" {
+			location := sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: f.File, URIBaseID: srcRoot},
+			}
+			if f.Line > 0 {
+				location.Region = &sarifRegion{StartLine: f.Line, StartColumn: f.Column}
+			}
+			result.Locations = []sarifLocation{{PhysicalLocation: location}}
+		}
+		if suppressed {
+			result.Suppressions = []sarifSuppression{{Kind: "inSource"}}
+		}
+
+		run.Results = append(run.Results, result)
+	}
+
+	for _, f := range report.Findings {
+		addResult(f, false)
+	}
+	for _, f := range report.Suppressed {
+		addResult(f, true)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
+	})
+}
+
+// dirURI returns the file URI of dir with a trailing slash, as SARIF requires
+// for base URIs.
+func dirURI(dir string) string {
+	u := url.URL{Scheme: "file", Path: filepath.ToSlash(dir)}
+	s := u.String()
+	if s[len(s)-1] != '/' {
+		s += "/"
+	}
+	return s
+}
+
+func ruleID(f validate.Finding) string {
+	if f.Rule == "" {
+		return "ink"
+	}
+	return f.Rule
+}
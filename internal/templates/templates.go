@@ -2,5 +2,5 @@ package templates
 
 import "embed"
 
-//go:embed plugin/*
+//go:embed plugin/* theme/*
 var Templates embed.FS
@@ -0,0 +1,246 @@
+package validate
+
+import (
+	"fmt"
+	"strings"
+
+	"inkdown-cli/internal/css"
+)
+
+// contrastPairs are the documented foreground/background combinations of a
+// theme and their WCAG 2.1 AA minimum: 4.5:1 for text, 3:1 for UI components.
+var contrastPairs = []struct {
+	Foreground string
+	Background string
+	Min        float64
+	Label      string
+}{
+	{"--text-normal", "--background-primary", 4.5, "body text"},
+	{"--text-normal", "--background-secondary", 4.5, "text on secondary surfaces"},
+	{"--text-muted", "--background-primary", 4.5, "muted text"},
+	{"--text-accent", "--background-primary", 4.5, "accent text"},
+	{"--link-color", "--background-primary", 4.5, "links"},
+	{"--code-normal", "--code-background", 4.5, "code blocks"},
+	{"--text-normal", "--selection-background", 4.5, "selected text"},
+	{"--interactive-accent", "--background-primary", 3, "interactive elements"},
+}
+
+// maxVarDepth bounds var() resolution so reference cycles terminate.
+const maxVarDepth = 16
+
+// checkContrast resolves the custom properties of a mode stylesheet and
+// reports every documented pair below its minimum contrast.
+func checkContrast(report *Report, rules Rules, file string, sheet *css.Stylesheet) {
+	vars := map[string]css.Declaration{}
+	sheet.Walk(func(r *css.Rule, inKeyframes bool) {
+		if inKeyframes {
+			return
+		}
+		for _, d := range r.Declarations {
+			if strings.HasPrefix(d.Property, "--") {
+				vars[d.Property] = d
+			}
+		}
+	})
+
+	white := css.Color{R: 1, G: 1, B: 1, A: 1}
+	colors := map[string]css.Color{}
+	unusable := map[string]bool{}
+	color := func(name string) (css.Color, bool) {
+		if c, ok := colors[name]; ok {
+			return c, true
+		}
+		if _, ok := vars[name]; !ok || unusable[name] {
+			// Missing variables are reported by required-variables.
+			return css.Color{}, false
+		}
+
+		value, err := resolveVar(vars, name, 0)
+		if err == nil {
+			var c css.Color
+			if c, err = css.ParseColor(value); err == nil {
+				colors[name] = c
+				return c, true
+			}
+		}
+
+		if f, ok := rules.finding("contrast", fmt.Sprintf("%s: cannot check contrast of %s: %v", file, name, err)); ok {
+			f.Severity = SeverityWarning
+			f.File, f.Line, f.Column = file, vars[name].Line, vars[name].Col
+			report.add(f)
+		}
+		unusable[name] = true
+		return css.Color{}, false
+	}
+
+	// Translucent backgrounds sit on the primary background.
+	base := white
+	if primary, ok := color("--background-primary"); ok {
+		base = primary.Over(white)
+	}
+
+	type check struct {
+		index    int
+		fg, bg   css.Color
+		opaqueBg css.Color
+		ratio    float64
+	}
+	var checks []check
+	for i, pair := range contrastPairs {
+		fg, ok := color(pair.Foreground)
+		if !ok {
+			continue
+		}
+		bg, ok := color(pair.Background)
+		if !ok {
+			continue
+		}
+
+		opaqueBg := bg.Over(base)
+		checks = append(checks, check{
+			index:    i,
+			fg:       fg,
+			bg:       bg,
+			opaqueBg: opaqueBg,
+			ratio:    css.Contrast(fg.Over(opaqueBg), opaqueBg),
+		})
+	}
+
+	// A foreground shared by several pairs gets one suggestion that passes
+	// on all of its backgrounds, so fixing one pair cannot break another.
+	type suggestion struct {
+		color css.Color
+		ok    bool
+	}
+	suggestions := map[string]suggestion{}
+	for _, c := range checks {
+		pair := contrastPairs[c.index]
+		if c.ratio >= pair.Min {
+			continue
+		}
+		if _, done := suggestions[pair.Foreground]; done {
+			continue
+		}
+
+		var targets []contrastTarget
+		for _, other := range checks {
+			if contrastPairs[other.index].Foreground == pair.Foreground {
+				targets = append(targets, contrastTarget{Background: other.opaqueBg, Min: contrastPairs[other.index].Min})
+			}
+		}
+		suggested, ok := nearestPassingColor(c.fg.Over(c.opaqueBg), targets)
+		suggestions[pair.Foreground] = suggestion{suggested, ok}
+	}
+
+	for _, c := range checks {
+		pair := contrastPairs[c.index]
+		if c.ratio >= pair.Min {
+			continue
+		}
+
+		message := fmt.Sprintf("%s: %s %s on %s %s has contrast %.2f:1, below %.1f:1 for %s",
+			file, pair.Foreground, c.fg.Hex(), pair.Background, c.bg.Hex(), c.ratio, pair.Min, pair.Label)
+		if s := suggestions[pair.Foreground]; s.ok {
+			message += fmt.Sprintf("; nearest %s passing on all its backgrounds is %s (%.2f:1 here)",
+				pair.Foreground, s.color.Hex(), css.Contrast(s.color, c.opaqueBg))
+		} else {
+			message += fmt.Sprintf("; no %s passes on all its backgrounds, change %s", pair.Foreground, pair.Background)
+		}
+
+		if f, ok := rules.finding("contrast", message); ok {
+			decl := vars[pair.Foreground]
+			f.File, f.Line, f.Column = file, decl.Line, decl.Col
+			report.add(f)
+		}
+	}
+}
+
+// resolveVar returns the value of a custom property with every var()
+// reference, and fallback, substituted.
+func resolveVar(vars map[string]css.Declaration, name string, depth int) (string, error) {
+	if depth > maxVarDepth {
+		return "", fmt.Errorf("%s references itself", name)
+	}
+	decl, ok := vars[name]
+	if !ok {
+		return "", fmt.Errorf("%s is not defined", name)
+	}
+	return substituteVars(vars, decl.Value, depth)
+}
+
+func substituteVars(vars map[string]css.Declaration, value string, depth int) (string, error) {
+	for {
+		start := strings.Index(value, "var(")
+		if start < 0 {
+			return value, nil
+		}
+
+		// Find the matching paren, allowing nested var() in the fallback.
+		end, level := -1, 0
+		for i := start + 3; i < len(value); i++ {
+			if value[i] == '(' {
+				level++
+			} else if value[i] == ')' {
+				level--
+				if level == 0 {
+					end = i
+					break
+				}
+			}
+		}
+		if end < 0 {
+			return "", fmt.Errorf("unterminated var() in %q", value)
+		}
+
+		name, fallback, hasFallback := strings.Cut(value[start+4:end], ",")
+		name = strings.TrimSpace(name)
+
+		resolved, err := resolveVar(vars, name, depth+1)
+		if err != nil && hasFallback {
+			resolved, err = substituteVars(vars, strings.TrimSpace(fallback), depth+1)
+		}
+		if err != nil {
+			return "", err
+		}
+
+		value = value[:start] + resolved + value[end+1:]
+	}
+}
+
+// contrastTarget is a background a foreground color must reach Min contrast
+// on.
+type contrastTarget struct {
+	Background css.Color
+	Min        float64
+}
+
+// nearestPassingColor returns the opaque color closest to fg, moving towards
+// white or black, that reaches the minimum contrast on every target. The
+// colors must be opaque.
+func nearestPassingColor(fg css.Color, targets []contrastTarget) (css.Color, bool) {
+	passes := func(c css.Color) bool {
+		for _, target := range targets {
+			if css.Contrast(c, target.Background) < target.Min {
+				return false
+			}
+		}
+		return true
+	}
+
+	best, bestT := css.Color{}, 2.0
+	for _, target := range []css.Color{{R: 1, G: 1, B: 1, A: 1}, {A: 1}} {
+		for step := 1; step <= 200; step++ {
+			t := float64(step) / 200
+			if t >= bestT {
+				break
+			}
+			// Round first so the suggested hex itself passes.
+			candidate, _ := css.ParseColor(fg.Mix(target, t).Hex())
+			if passes(candidate) {
+				best, bestT = candidate, t
+				break
+			}
+		}
+	}
+	return best, bestT <= 1
+}
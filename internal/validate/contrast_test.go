@@ -0,0 +1,92 @@
+package validate
+
+import (
+	"regexp"
+	"testing"
+
+	"inkdown-cli/internal/css"
+)
+
+func mustColor(t *testing.T, value string) css.Color {
+	t.Helper()
+	c, err := css.ParseColor(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestNearestPassingColor(t *testing.T) {
+	targets := []contrastTarget{
+		{mustColor(t, "#ffffff"), 4.5},
+		{mustColor(t, "#c8d8ff"), 4.5},
+	}
+
+	got, ok := nearestPassingColor(mustColor(t, "#888888"), targets)
+	if !ok {
+		t.Fatal("no passing color found")
+	}
+	for _, target := range targets {
+		if ratio := css.Contrast(got, target.Background); ratio < target.Min {
+			t.Errorf("suggestion %s has contrast %.2f:1 on %s, want at least %.1f:1", got.Hex(), ratio, target.Background.Hex(), target.Min)
+		}
+	}
+
+	// The first target alone is satisfied by a lighter color.
+	single, _ := nearestPassingColor(mustColor(t, "#888888"), targets[:1])
+	if single.Luminance() <= got.Luminance() {
+		t.Errorf("suggestion for both backgrounds %s should be darker than for one, %s", got.Hex(), single.Hex())
+	}
+
+	// Mid grey on mid grey cannot reach 21:1 in either direction.
+	if _, ok := nearestPassingColor(mustColor(t, "#777777"), []contrastTarget{{mustColor(t, "#777777"), 21}}); ok {
+		t.Error("found a passing color for an unreachable minimum")
+	}
+}
+
+var suggestionPattern = regexp.MustCompile(`passing on all its backgrounds is (#[0-9a-f]{6})`)
+
+func TestCheckContrastSharedForeground(t *testing.T) {
+	sheet, err := css.Parse(`.theme-light {
+  --background-primary: #ffffff;
+  --background-secondary: #f0f0f0;
+  --selection-background: #c8d8ff;
+  --text-normal: #888888;
+}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rules, err := LoadRules(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report := newReport("theme", ".")
+	checkContrast(report, rules, "theme.css", sheet)
+
+	if len(report.Findings) != 3 {
+		t.Fatalf("got %d findings, want one per --text-normal pair: %v", len(report.Findings), report.Findings)
+	}
+	var suggestion string
+	for _, f := range report.Findings {
+		if f.Line != 5 || f.Column != 3 {
+			t.Errorf("finding at %d:%d, want the --text-normal declaration at 5:3", f.Line, f.Column)
+		}
+		m := suggestionPattern.FindStringSubmatch(f.Message)
+		if m == nil {
+			t.Fatalf("no suggestion in %q", f.Message)
+		}
+		if suggestion == "" {
+			suggestion = m[1]
+		} else if m[1] != suggestion {
+			t.Errorf("suggestions differ: %s and %s", suggestion, m[1])
+		}
+	}
+
+	fixed := mustColor(t, suggestion)
+	for _, bg := range []string{"#ffffff", "#f0f0f0", "#c8d8ff"} {
+		if ratio := css.Contrast(fixed, mustColor(t, bg)); ratio < 4.5 {
+			t.Errorf("suggestion %s has contrast %.2f:1 on %s", suggestion, ratio, bg)
+		}
+	}
+}
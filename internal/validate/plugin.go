@@ -1,33 +1,41 @@
 package validate
 
 import (
-	"encoding/json"
 	"fmt"
+	"inkdown-cli/config"
+	"inkdown-cli/internal/jsscan"
 	"inkdown-cli/utils"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 )
 
-var allowedExtensions = map[string]bool{
-	".ts": true, ".js": true, ".json": true, ".md": true,
-	".css": true, ".png": true, ".jpg": true, ".jpeg": true,
-	".svg": true, ".gitignore": true, ".ym": true, ".yaml": true,
-	".yml": true, ".mjs": true,
+// sourceExtensions are the files scanned for forbidden usages.
+var sourceExtensions = map[string]bool{
+	".ts": true, ".js": true, ".mjs": true, ".tsx": true, ".jsx": true,
 }
 
-var forbiddenTokens = []struct {
-	Token   string
-	Message string
+// forbiddenUsages maps what plugin sources may not reference to the rule
+// that reports it. Globals and members match by name; imports match by module
+// prefix.
+var forbiddenUsages = []struct {
+	Kind jsscan.UsageKind
+	Name string
+	Rule string
 }{
-	{"window.", "Direct access to \"window\" is forbidden. Use platform-agnostic abstractions."},
-	{"document.", "Direct access to \"document\" is forbidden. Use platform-agnostic abstractions."},
-	{"innerHTML", "Usage of \"innerHTML\" is forbidden."},
-	{"outerHTML", "Usage of \"outerHTML\" is forbidden."},
-	{"@codemirror/", "Direct imports from \"@codemirror/\" are forbidden. Use @inkdown/core editor abstractions."},
-	{"@tauri-apps/", "Direct imports from \"@tauri-apps/\" are forbidden. Use @inkdown/core native abstractions."},
+	{jsscan.Global, "window", "no-window"},
+	{jsscan.Global, "document", "no-document"},
+	{jsscan.Member, "innerHTML", "no-inner-html"},
+	{jsscan.Member, "outerHTML", "no-outer-html"},
+	{jsscan.Import, "@codemirror/", "no-codemirror-import"},
+	{jsscan.Import, "@tauri-apps/", "no-tauri-import"},
 }
 
+// ignoreDirective suppresses findings on the following line, either for the
+// rule ids listed after it or for every rule when none are listed.
+const ignoreDirective = "ink-ignore-next-line"
+
 type PackageJSON interface {
 	GetName() string
 	GetVersion() string
@@ -38,41 +46,43 @@ type Package struct {
 	Version string `json:"version"`
 }
 
-func ValidatePlugin(dir string) error {
+// ValidatePlugin checks the plugin project in dir. The report lists every
+// finding; the error is non-nil when any of them is an error.
+func ValidatePlugin(dir string) (*Report, error) {
 	utils.Info("Validating plugin in: %s", dir)
 
-	hasErrors := false
+	report := newReport("plugin", dir)
+
+	rules, err := LoadRules(dir)
+	if err != nil {
+		report.errorf(ruleProjectConfig, config.ProjectFile, err.Error())
+	}
 
 	if _, err := os.Stat(filepath.Join(dir, "manifest.json")); os.IsNotExist(err) {
-		utils.Error("Missing 'manifest.json'")
-		hasErrors = true
+		report.errorf(ruleManifest, "manifest.json", "Missing 'manifest.json'")
 	} else {
 		content, err := os.ReadFile(filepath.Join(dir, "manifest.json"))
 		if err == nil {
-			var pkg Package
-			if err := json.Unmarshal(content, &pkg); err != nil {
-				utils.Error("Invalid 'manifest.json': %v", err)
-				hasErrors = true
-			} else {
-				if pkg.Name == "" {
-					utils.Error("manifest.json missing 'name'")
-					hasErrors = true
-				}
-				if pkg.Version == "" {
-					utils.Error("manifest.json missing 'version'")
-					hasErrors = true
-				}
-			}
+			validateManifest(report, "manifest.json", content, pluginManifestSchema)
 		}
 	}
 
 	srcDir := filepath.Join(dir, "src")
 	if _, err := os.Stat(srcDir); os.IsNotExist(err) {
-		utils.Error("Missing 'src' directory. Plugin source must be in 'src'.")
-		return fmt.Errorf("plugin validation failed")
+		report.errorf(ruleSourceDir, "src", "Missing 'src' directory. Plugin source must be in 'src'.")
+		return report, fmt.Errorf("plugin validation failed")
 	}
 
-	err := filepath.Walk(srcDir, func(path string, info os.FileInfo, err error) error {
+	allowedExtensions := map[string]bool{}
+	for _, ext := range rules.option("allowed-extensions", "extensions") {
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		allowedExtensions[strings.ToLower(ext)] = true
+	}
+	allowedFiles := rules.option("allowed-extensions", "files")
+
+	err = filepath.Walk(srcDir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
@@ -82,28 +92,18 @@ func ValidatePlugin(dir string) error {
 
 		ext := strings.ToLower(filepath.Ext(path))
 		name := info.Name()
-		if !allowedExtensions[ext] && name != "LICENSE" && name != "README" && name != "README.md" {
-			utils.Error("Forbidden file type found: %s (Extension \"%s\" is not in whitelist)", path, ext)
-			hasErrors = true
+		if !allowedExtensions[ext] && !slices.Contains(allowedFiles, name) {
+			message := fmt.Sprintf("Forbidden file type found: %s (Extension \"%s\" is not in whitelist)", path, ext)
+			if f, ok := rules.finding("allowed-extensions", message); ok {
+				f.File = report.rel(path)
+				report.add(f)
+			}
 		}
 
-		if ext == ".ts" || ext == ".js" || ext == ".mjs" {
+		if sourceExtensions[ext] {
 			content, err := os.ReadFile(path)
 			if err == nil {
-				lines := strings.Split(string(content), "\n")
-				for i, line := range lines {
-					trimmed := strings.TrimSpace(line)
-					if strings.HasPrefix(trimmed, "//") || strings.HasPrefix(trimmed, "*") {
-						continue
-					}
-					for _, rule := range forbiddenTokens {
-						if strings.Contains(line, rule.Token) {
-							utils.Error("Forbidden token \"%s\" found in %s:%d", rule.Token, path, i+1)
-							utils.Note(rule.Message)
-							hasErrors = true
-						}
-					}
-				}
+				scanSource(report, rules, path, string(content), ext == ".jsx" || ext == ".tsx")
 			}
 		}
 
@@ -111,13 +111,89 @@ func ValidatePlugin(dir string) error {
 	})
 
 	if err != nil {
-		return fmt.Errorf("error scanning files: %v", err)
+		return report, fmt.Errorf("error scanning files: %v", err)
 	}
 
-	if hasErrors {
-		return fmt.Errorf("plugin validation failed")
+	report.summary()
+
+	if report.HasErrors() {
+		return report, fmt.Errorf("plugin validation failed")
 	}
 
+	report.Valid = true
 	utils.Success("Plugin validation passed!")
-	return nil
+	return report, nil
+}
+
+// scanSource reports every forbidden usage in a JavaScript or TypeScript file.
+func scanSource(report *Report, rules Rules, path string, src string, jsx bool) {
+	file, err := jsscan.Scan(src, jsx)
+	if err != nil {
+		f := Finding{
+			Severity: SeverityError,
+			Message:  fmt.Sprintf("Could not parse %s: %v", path, err),
+			File:     report.rel(path),
+			Rule:     ruleParse,
+		}
+		if syntaxErr, ok := err.(*jsscan.SyntaxError); ok {
+			f.Line, f.Column = syntaxErr.Line, syntaxErr.Col
+		}
+		report.add(f)
+		return
+	}
+
+	ignored := ignoredLines(file.Comments)
+
+	for _, usage := range file.Usages {
+		for _, forbidden := range forbiddenUsages {
+			if forbidden.Kind != usage.Kind {
+				continue
+			}
+			if usage.Kind == jsscan.Import && !strings.HasPrefix(usage.Name, forbidden.Name) {
+				continue
+			}
+			if usage.Kind != jsscan.Import && usage.Name != forbidden.Name {
+				continue
+			}
+
+			message := fmt.Sprintf("Forbidden %s \"%s\" found in %s:%d:%d", usage.Kind, usage.Name, path, usage.Line, usage.Col)
+			f, ok := rules.finding(forbidden.Rule, message)
+			if !ok {
+				continue
+			}
+			f.File = report.rel(path)
+			f.Line = usage.Line
+			f.Column = usage.Col
+
+			if ids := ignored[usage.Line]; slices.Contains(ids, "*") || slices.Contains(ids, forbidden.Rule) {
+				report.suppress(f)
+				continue
+			}
+			report.add(f)
+		}
+	}
+}
+
+// ignoredLines maps each line following an ignore directive to the rule ids
+// it suppresses; "*" stands for every rule.
+func ignoredLines(comments []jsscan.Token) map[int][]string {
+	ignored := map[int][]string{}
+	for _, c := range comments {
+		text := strings.TrimSpace(c.Value)
+		rest, ok := strings.CutPrefix(text, ignoreDirective)
+		if !ok || (rest != "" && rest[0] != ' ' && rest[0] != '\t') {
+			continue
+		}
+
+		ids := strings.FieldsFunc(rest, func(r rune) bool {
+			return r == ',' || r == ' ' || r == '\t'
+		})
+		if len(ids) == 0 {
+			ids = []string{"*"}
+		}
+		// The directive applies to the line after the end of the comment.
+		line := c.Line + strings.Count(c.Value, "\n") + 1
+		ignored[line] = append(ignored[line], ids...)
+	}
+	return ignored
 }
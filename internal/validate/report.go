@@ -0,0 +1,98 @@
+package validate
+
+import (
+	"path/filepath"
+
+	"inkdown-cli/utils"
+)
+
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Finding is a single validation problem.
+type Finding struct {
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+	Hint     string `json:"hint,omitempty"`
+	// File is relative to the validated directory. Line and Column are
+	// 1-based and zero when unknown.
+	File   string `json:"file,omitempty"`
+	Line   int    `json:"line,omitempty"`
+	Column int    `json:"column,omitempty"`
+	// Pointer is the JSON pointer of the offending value in a JSON file.
+	Pointer string `json:"pointer,omitempty"`
+	// Rule is the id of the rule that produced the finding, if any.
+	Rule string `json:"rule,omitempty"`
+	Docs string `json:"docs,omitempty"`
+}
+
+// Report collects the findings of a validation run.
+type Report struct {
+	Kind     string    `json:"kind"`
+	Dir      string    `json:"dir"`
+	Valid    bool      `json:"valid"`
+	Findings []Finding `json:"findings"`
+	// Suppressed holds findings silenced by ink-ignore-next-line comments.
+	Suppressed []Finding `json:"suppressed"`
+}
+
+func newReport(kind, dir string) *Report {
+	return &Report{Kind: kind, Dir: dir, Findings: []Finding{}, Suppressed: []Finding{}}
+}
+
+// add records a finding and prints it for humans.
+func (r *Report) add(f Finding) {
+	r.Findings = append(r.Findings, f)
+
+	if f.Severity == SeverityWarning {
+		utils.Warn("%s", f.Message)
+	} else {
+		utils.Error("%s", f.Message)
+	}
+	if f.Hint != "" {
+		utils.Note("%s", f.Hint)
+	}
+	if f.Docs != "" {
+		utils.Note("See %s", f.Docs)
+	}
+}
+
+// suppress records a finding that an inline comment silenced.
+func (r *Report) suppress(f Finding) {
+	r.Suppressed = append(r.Suppressed, f)
+}
+
+// summary prints the suppressed findings so they do not go unnoticed.
+func (r *Report) summary() {
+	if len(r.Suppressed) == 0 {
+		return
+	}
+
+	utils.Note("%d finding(s) suppressed by ink-ignore-next-line comments:", len(r.Suppressed))
+	for _, f := range r.Suppressed {
+		utils.Printf("  %s:%d:%d  %s\n", f.File, f.Line, f.Column, f.Rule)
+	}
+}
+
+func (r *Report) errorf(rule string, file string, message string) {
+	r.add(Finding{Severity: SeverityError, Message: message, File: file, Rule: rule})
+}
+
+func (r *Report) HasErrors() bool {
+	for _, f := range r.Findings {
+		if f.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// rel returns path relative to the report directory for use in findings.
+func (r *Report) rel(path string) string {
+	if rel, err := filepath.Rel(r.Dir, path); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return path
+}
@@ -0,0 +1,160 @@
+package validate
+
+import (
+	"fmt"
+	"maps"
+	"sort"
+	"strings"
+
+	"inkdown-cli/config"
+)
+
+// Rule severities as written in .inkrc.json.
+const (
+	RuleError = "error"
+	RuleWarn  = "warn"
+	RuleOff   = "off"
+)
+
+const rulesDocsURL = "https://github.com/inkdown/inkdown-cli/blob/main/docs/validation-rules.md"
+
+// Ids of the built-in checks that cannot be configured. They are reported
+// alongside rule findings so every finding carries a rule id.
+const (
+	ruleManifest      = "manifest"
+	ruleSourceDir     = "src-directory"
+	ruleParse         = "parse-error"
+	ruleProjectConfig = "project-config"
+	ruleCSSFile       = "css-file"
+)
+
+// Rule is a plugin or theme validation check that projects can turn off or
+// downgrade, and for some rules tune through options.
+type Rule struct {
+	ID       string `json:"id"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+	Docs     string `json:"docs"`
+	// Options are the rule's settings by name, e.g. the file extensions
+	// allowed-extensions accepts. Only the names set by default exist.
+	Options map[string][]string `json:"options,omitempty"`
+}
+
+var defaultRules = []Rule{
+	{ID: "no-window", Severity: RuleError, Message: "Direct access to \"window\" is forbidden. Use platform-agnostic abstractions."},
+	{ID: "no-document", Severity: RuleError, Message: "Direct access to \"document\" is forbidden. Use platform-agnostic abstractions."},
+	{ID: "no-inner-html", Severity: RuleError, Message: "Usage of \"innerHTML\" is forbidden."},
+	{ID: "no-outer-html", Severity: RuleError, Message: "Usage of \"outerHTML\" is forbidden."},
+	{ID: "no-codemirror-import", Severity: RuleError, Message: "Direct imports from \"@codemirror/\" are forbidden. Use @inkdown/core editor abstractions."},
+	{ID: "no-tauri-import", Severity: RuleError, Message: "Direct imports from \"@tauri-apps/\" are forbidden. Use @inkdown/core native abstractions."},
+	{ID: "allowed-extensions", Severity: RuleError, Message: "Only source, style, image, documentation and config files may be shipped in 'src'.", Options: map[string][]string{
+		"extensions": {
+			".ts", ".tsx", ".js", ".jsx", ".mjs", ".json", ".md", ".css", ".png", ".jpg", ".jpeg",
+			".svg", ".gitignore", ".ym", ".yaml", ".yml",
+		},
+		"files": {"LICENSE", "README", "README.md"},
+	}},
+	{ID: "no-remote-import", Severity: RuleError, Message: "Themes may not import stylesheets from the network. Ship them with the theme."},
+	{ID: "no-remote-url", Severity: RuleError, Message: "Themes may not load resources from the network. Ship fonts and images with the theme or use data: URLs."},
+	{ID: "no-internal-selectors", Severity: RuleError, Message: "Style the documented surface (the .theme-<mode> class, elements and Inkdown variables), not app internals."},
+	{ID: "contrast", Severity: RuleError, Message: "Text needs 4.5:1 and interactive elements 3:1 contrast against their background (WCAG 2.1 AA)."},
+	{ID: "required-variables", Severity: RuleError, Message: "Every mode must define all Inkdown CSS variables so the app renders consistently.", Options: map[string][]string{
+		"variables": {
+			"--background-primary", "--background-secondary", "--border-color",
+			"--text-normal", "--text-muted", "--text-accent", "--link-color",
+			"--interactive-accent", "--code-background", "--code-normal",
+			"--selection-background",
+		},
+	}},
+}
+
+// Rules maps rule ids to their effective configuration.
+type Rules map[string]Rule
+
+// LoadRules returns the built-in rules with the overrides from the project
+// file in dir applied. On error the returned rules are still usable and
+// include every valid override.
+func LoadRules(dir string) (Rules, error) {
+	rules := Rules{}
+	for _, rule := range defaultRules {
+		rule.Docs = rulesDocsURL + "#" + rule.ID
+		rules[rule.ID] = rule
+	}
+
+	project, err := config.LoadProject(dir)
+	if err != nil {
+		return rules, err
+	}
+
+	ids := make([]string, 0, len(project.Validate.Rules))
+	for id := range project.Validate.Rules {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var problems []string
+	for _, id := range ids {
+		override := project.Validate.Rules[id]
+		rule, ok := rules[id]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("unknown rule %q", id))
+			continue
+		}
+
+		switch override.Severity {
+		case "":
+		case RuleError, RuleWarn, RuleOff:
+			rule.Severity = override.Severity
+		default:
+			problems = append(problems, fmt.Sprintf("rule %q has invalid severity %q (want error, warn or off)", id, override.Severity))
+		}
+
+		names := make([]string, 0, len(override.Options))
+		for name := range override.Options {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		// The defaults are shared, so copy before overriding them.
+		rule.Options = maps.Clone(rule.Options)
+		for _, name := range names {
+			if _, ok := rule.Options[name]; !ok {
+				problems = append(problems, fmt.Sprintf("rule %q has no option %q", id, name))
+				continue
+			}
+			rule.Options[name] = override.Options[name]
+		}
+		rules[id] = rule
+	}
+
+	if len(problems) > 0 {
+		return rules, fmt.Errorf("%s: %s", config.ProjectFile, strings.Join(problems, "; "))
+	}
+	return rules, nil
+}
+
+// finding builds a finding for rule, or returns false when the rule is off.
+func (r Rules) finding(id string, message string) (Finding, bool) {
+	rule := r[id]
+	if rule.Severity == RuleOff {
+		return Finding{}, false
+	}
+
+	severity := SeverityError
+	if rule.Severity == RuleWarn {
+		severity = SeverityWarning
+	}
+
+	return Finding{
+		Severity: severity,
+		Message:  message,
+		Hint:     rule.Message,
+		Rule:     rule.ID,
+		Docs:     rule.Docs,
+	}, true
+}
+
+// option returns the values of a rule option.
+func (r Rules) option(id, name string) []string {
+	return r[id].Options[name]
+}
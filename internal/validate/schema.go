@@ -0,0 +1,271 @@
+package validate
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// PluginManifestSchemaVersion is the version of the embedded manifest schema.
+const PluginManifestSchemaVersion = 1
+
+// PluginManifestSchema is the JSON Schema of plugin manifest.json files,
+// printed by `ink plugin schema` for editor integration.
+//
+//go:embed schema/plugin-manifest.v1.json
+var PluginManifestSchema []byte
+
+// schema is the subset of JSON Schema the manifest schema uses.
+// ErrorMessage is a non-standard keyword replacing the generic pattern error.
+type schema struct {
+	Type         string             `json:"type"`
+	Required     []string           `json:"required"`
+	Properties   map[string]*schema `json:"properties"`
+	Items        *schema            `json:"items"`
+	Pattern      string             `json:"pattern"`
+	Format       string             `json:"format"`
+	MinLength    *int               `json:"minLength"`
+	MaxLength    *int               `json:"maxLength"`
+	MaxItems     *int               `json:"maxItems"`
+	UniqueItems  bool               `json:"uniqueItems"`
+	ErrorMessage string             `json:"errorMessage"`
+}
+
+// schemaError is a violation at a JSON pointer into the document.
+type schemaError struct {
+	Pointer string
+	Message string
+	Warning bool
+}
+
+var pluginManifestSchema = mustParseSchema(PluginManifestSchema)
+
+func mustParseSchema(data []byte) *schema {
+	var s schema
+	if err := json.Unmarshal(data, &s); err != nil {
+		panic(fmt.Sprintf("invalid embedded schema: %v", err))
+	}
+	return &s
+}
+
+// validateSchema checks doc against s. Properties the schema does not
+// declare are reported as warnings.
+func validateSchema(s *schema, doc interface{}) []schemaError {
+	var errs []schemaError
+	s.check(doc, "", &errs)
+	return errs
+}
+
+func (s *schema) check(v interface{}, ptr string, errs *[]schemaError) {
+	fail := func(format string, args ...interface{}) {
+		*errs = append(*errs, schemaError{Pointer: ptr, Message: fmt.Sprintf(format, args...)})
+	}
+
+	switch s.Type {
+	case "object":
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			fail("must be an object")
+			return
+		}
+		for _, key := range s.Required {
+			if _, ok := obj[key]; !ok {
+				fail("missing required field %q", key)
+			}
+		}
+		keys := make([]string, 0, len(obj))
+		for key := range obj {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			child := ptr + "/" + escapePointer(key)
+			if prop, ok := s.Properties[key]; ok {
+				prop.check(obj[key], child, errs)
+			} else {
+				*errs = append(*errs, schemaError{Pointer: child, Message: fmt.Sprintf("unknown field %q", key), Warning: true})
+			}
+		}
+
+	case "array":
+		arr, ok := v.([]interface{})
+		if !ok {
+			fail("must be an array")
+			return
+		}
+		if s.MaxItems != nil && len(arr) > *s.MaxItems {
+			fail("must have at most %d items", *s.MaxItems)
+		}
+		if s.UniqueItems {
+			seen := map[string]bool{}
+			for i, item := range arr {
+				key, _ := json.Marshal(item)
+				if seen[string(key)] {
+					*errs = append(*errs, schemaError{Pointer: ptr + "/" + strconv.Itoa(i), Message: "duplicate item"})
+				}
+				seen[string(key)] = true
+			}
+		}
+		if s.Items != nil {
+			for i, item := range arr {
+				s.Items.check(item, ptr+"/"+strconv.Itoa(i), errs)
+			}
+		}
+
+	case "string":
+		str, ok := v.(string)
+		if !ok {
+			fail("must be a string")
+			return
+		}
+		n := utf8.RuneCountInString(str)
+		if s.MinLength != nil && n < *s.MinLength {
+			if *s.MinLength == 1 {
+				fail("must not be empty")
+			} else {
+				fail("must be at least %d characters", *s.MinLength)
+			}
+			return
+		}
+		if s.MaxLength != nil && n > *s.MaxLength {
+			fail("must be at most %d characters", *s.MaxLength)
+		}
+		if s.Pattern != "" && !regexp.MustCompile(s.Pattern).MatchString(str) {
+			if s.ErrorMessage != "" {
+				fail("%s", s.ErrorMessage)
+			} else {
+				fail("must match %s", s.Pattern)
+			}
+		}
+		if s.Format == "uri" && !isHTTPURL(str) {
+			fail("must be an absolute http(s) URL")
+		}
+	}
+}
+
+func isHTTPURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+func escapePointer(key string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+}
+
+// pointerPositions maps the JSON pointer of every value in data to its
+// 1-based line and column.
+func pointerPositions(data []byte) map[string][2]int {
+	offsets := map[string]int{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+
+	// InputOffset is the end of the previous token; skip the separators
+	// before the value to find where it starts.
+	start := func() int {
+		off := int(dec.InputOffset())
+		for off < len(data) && strings.IndexByte(" \t\r\n:,", data[off]) >= 0 {
+			off++
+		}
+		return off
+	}
+
+	var walk func(ptr string) error
+	walk = func(ptr string) error {
+		offsets[ptr] = start()
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		switch tok {
+		case json.Delim('{'):
+			for dec.More() {
+				key, err := dec.Token()
+				if err != nil {
+					return err
+				}
+				if err := walk(ptr + "/" + escapePointer(key.(string))); err != nil {
+					return err
+				}
+			}
+			_, err = dec.Token()
+		case json.Delim('['):
+			for i := 0; dec.More(); i++ {
+				if err := walk(ptr + "/" + strconv.Itoa(i)); err != nil {
+					return err
+				}
+			}
+			_, err = dec.Token()
+		}
+		return err
+	}
+	walk("")
+
+	positions := make(map[string][2]int, len(offsets))
+	for ptr, off := range offsets {
+		line, col := offsetPosition(data, off)
+		positions[ptr] = [2]int{line, col}
+	}
+	return positions
+}
+
+// offsetPosition returns the 1-based line and column, in runes, of the byte
+// at off in data.
+func offsetPosition(data []byte, off int) (int, int) {
+	before := data[:off]
+	line := bytes.Count(before, []byte("\n")) + 1
+	col := utf8.RuneCount(before[bytes.LastIndexByte(before, '\n')+1:]) + 1
+	return line, col
+}
+
+// validateManifest reports JSON syntax errors and schema violations of a
+// manifest file, located by JSON pointer, line and column.
+func validateManifest(report *Report, file string, content []byte, s *schema) {
+	var doc interface{}
+	if err := json.Unmarshal(content, &doc); err != nil {
+		f := Finding{
+			Severity: SeverityError,
+			Message:  fmt.Sprintf("Invalid '%s': %v", file, err),
+			File:     file,
+			Rule:     ruleManifest,
+		}
+		if syntaxErr, ok := err.(*json.SyntaxError); ok {
+			// Offset counts the offending byte, unless the input ended early.
+			off := int(syntaxErr.Offset)
+			if off > 0 && off <= len(content) && !strings.HasPrefix(syntaxErr.Error(), "unexpected end") {
+				off--
+			}
+			f.Line, f.Column = offsetPosition(content, off)
+		}
+		report.add(f)
+		return
+	}
+
+	positions := pointerPositions(content)
+	for _, e := range validateSchema(s, doc) {
+		pointer := e.Pointer
+		if pointer == "" {
+			pointer = "/"
+		}
+
+		f := Finding{
+			Severity: SeverityError,
+			Message:  fmt.Sprintf("%s: %s %s", file, pointer, e.Message),
+			File:     file,
+			Pointer:  pointer,
+			Rule:     ruleManifest,
+		}
+		if e.Warning {
+			f.Severity = SeverityWarning
+		}
+		if pos, ok := positions[e.Pointer]; ok {
+			f.Line, f.Column = pos[0], pos[1]
+		}
+		report.add(f)
+	}
+}
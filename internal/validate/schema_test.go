@@ -0,0 +1,134 @@
+package validate
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"inkdown-cli/internal/generator"
+)
+
+const validManifest = `{
+  "id": "my-plugin",
+  "name": "My plugin",
+  "version": "1.0.0",
+  "minAppVersion": "0.1.0",
+  "description": "A plugin",
+  "author": "Someone"
+}`
+
+// manifestFindings validates content as manifest.json and formats the
+// findings as "severity line:column pointer message".
+func manifestFindings(content string) []string {
+	report := newReport("plugin", ".")
+	validateManifest(report, "manifest.json", []byte(content), pluginManifestSchema)
+
+	var got []string
+	for _, f := range report.Findings {
+		got = append(got, fmt.Sprintf("%s %d:%d %s", f.Severity, f.Line, f.Column, f.Pointer))
+	}
+	return got
+}
+
+func TestValidateManifest(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{"valid", validManifest, nil},
+		{
+			name:    "invalid id and unknown field",
+			content: "{\n  \"id\": \"My Plugin\",\n  \"name\": \"x\", \"version\": \"1.0.0\", \"minAppVersion\": \"0.1.0\",\n  \"description\": \"d\", \"author\": \"a\", \"color\": 1\n}",
+			want:    []string{"warning 4:47 /color", "error 2:9 /id"},
+		},
+		{
+			name:    "missing fields",
+			content: `{"id": "ab"}`,
+			want: []string{
+				"error 1:1 /", "error 1:1 /", "error 1:1 /", "error 1:1 /", "error 1:1 /",
+			},
+		},
+		{
+			name:    "invalid character",
+			content: "{\n  \"id\": x\n}",
+			want:    []string{"error 2:9 "},
+		},
+		{
+			name:    "trailing comma",
+			content: "{\n  \"id\": \"ab\",\n}",
+			want:    []string{"error 3:1 "},
+		},
+		{
+			name:    "multibyte characters before the error",
+			content: `{"name": "héllo" x}`,
+			want:    []string{"error 1:18 "},
+		},
+		{
+			name:    "unexpected end",
+			content: `{"id": "ab"`,
+			want:    []string{"error 1:12 "},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := manifestFindings(tt.content)
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("findings = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPointerPositions(t *testing.T) {
+	positions := pointerPositions([]byte("{\n  \"a\": [1, {\"b~/c\": true}],\n  \"é\": \"x\"\n}"))
+
+	want := map[string][2]int{
+		"":            {1, 1},
+		"/a":          {2, 8},
+		"/a/0":        {2, 9},
+		"/a/1":        {2, 12},
+		"/a/1/b~0~1c": {2, 21},
+		"/é":          {3, 8},
+	}
+	for ptr, pos := range want {
+		if got := positions[ptr]; got != pos {
+			t.Errorf("position of %q = %v, want %v", ptr, got, pos)
+		}
+	}
+}
+
+// TestPluginIDMatchesSchema checks that `ink plugin init --name` writes ids
+// the manifest schema accepts.
+func TestPluginIDMatchesSchema(t *testing.T) {
+	pattern := regexp.MustCompile(pluginManifestSchema.Properties["id"].Pattern)
+	maxLength := *pluginManifestSchema.Properties["id"].MaxLength
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"My Plugin", "my-plugin"},
+		{"  Word Count!! ", "word-count"},
+		{"Café_Notes v2", "caf-notes-v2"},
+		{"--already-an-id--", "already-an-id"},
+		{strings.Repeat("Ab ", 30), ""},
+		{strings.Repeat("a", 63) + " b", strings.Repeat("a", 63)},
+	}
+
+	for _, tt := range tests {
+		id := generator.PluginID(tt.name)
+		if tt.want != "" && id != tt.want {
+			t.Errorf("PluginID(%q) = %q, want %q", tt.name, id, tt.want)
+		}
+		if !pattern.MatchString(id) || len(id) > maxLength {
+			t.Errorf("PluginID(%q) = %q, which the manifest schema rejects", tt.name, id)
+		}
+	}
+
+	if id := generator.PluginID("日本語"); id != "" {
+		t.Errorf(`PluginID("日本語") = %q, want ""`, id)
+	}
+}
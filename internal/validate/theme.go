@@ -3,67 +3,149 @@ package validate
 import (
 	"encoding/json"
 	"fmt"
+	"inkdown-cli/config"
 	"inkdown-cli/utils"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strings"
 )
 
+// ModeName is the rule for theme mode names, which become the file names of
+// their stylesheets.
+var ModeName = regexp.MustCompile(`^[a-z][a-z0-9-]*$`)
+
 type ThemeManifest struct {
-	Name    string   `json:"name"`
-	Version string   `json:"version"`
-	Modes   []string `json:"modes"`
+	Name        string   `json:"name"`
+	Version     string   `json:"version"`
+	Description string   `json:"description,omitempty"`
+	Author      string   `json:"author,omitempty"`
+	Modes       []string `json:"modes"`
+}
+
+// CSSModes returns the modes the theme ships a stylesheet for.
+func (t *ThemeManifest) CSSModes() []string {
+	if len(t.Modes) == 0 {
+		return []string{"dark"} // default if not specified? Assuming similar logic to registry
+	}
+	return t.Modes
+}
+
+// LoadThemeManifest reads theme.json in dir.
+func LoadThemeManifest(dir string) (*ThemeManifest, error) {
+	content, err := os.ReadFile(filepath.Join(dir, "theme.json"))
+	if err != nil {
+		return nil, fmt.Errorf("could not read theme.json: %v", err)
+	}
+
+	theme, err := parseThemeManifest(content)
+	if err != nil {
+		return nil, err
+	}
+	if problems := theme.modeProblems(); len(problems) > 0 {
+		return nil, fmt.Errorf("invalid theme.json: %s", strings.Join(problems, "; "))
+	}
+	return theme, nil
 }
 
-func ValidateTheme(dir string) error {
+// modeProblems describes the invalid and duplicate modes of t. Modes are
+// joined into file paths, so a theme with problems must not be used.
+func (t *ThemeManifest) modeProblems() []string {
+	var problems []string
+	seen := map[string]bool{}
+	for _, mode := range t.Modes {
+		switch {
+		case !ModeName.MatchString(mode):
+			problems = append(problems, fmt.Sprintf("invalid mode %q: use lowercase letters, digits and dashes", mode))
+		case seen[mode]:
+			problems = append(problems, fmt.Sprintf("duplicate mode %q", mode))
+		}
+		seen[mode] = true
+	}
+	return problems
+}
+
+// parseThemeManifest decodes the content of theme.json.
+func parseThemeManifest(content []byte) (*ThemeManifest, error) {
+	var theme ThemeManifest
+	if err := json.Unmarshal(content, &theme); err != nil {
+		return nil, fmt.Errorf("invalid theme.json: %v", err)
+	}
+	return &theme, nil
+}
+
+// ThemeOptions enables optional theme checks.
+type ThemeOptions struct {
+	// A11y checks the WCAG contrast of documented color pairs in every mode.
+	A11y bool
+}
+
+// ValidateTheme checks the theme project in dir. The report lists every
+// finding; the error is non-nil when any of them is an error.
+func ValidateTheme(dir string, opts ThemeOptions) (*Report, error) {
 	utils.Info("Validating theme in: %s", dir)
 
-	hasErrors := false
+	report := newReport("theme", dir)
+
+	rules, err := LoadRules(dir)
+	if err != nil {
+		report.errorf(ruleProjectConfig, config.ProjectFile, err.Error())
+	}
 
 	// 1. Check theme.json
 	manifestPath := filepath.Join(dir, "theme.json")
 	if _, err := os.Stat(manifestPath); os.IsNotExist(err) {
-		utils.Error("Missing 'theme.json'")
-		return fmt.Errorf("theme validation failed")
+		report.errorf(ruleManifest, "theme.json", "Missing 'theme.json'")
+		return report, fmt.Errorf("theme validation failed")
 	}
 
 	content, err := os.ReadFile(manifestPath)
 	if err != nil {
-		return fmt.Errorf("could not read theme.json: %v", err)
+		return report, fmt.Errorf("could not read theme.json: %v", err)
 	}
 
-	var theme ThemeManifest
-	if err := json.Unmarshal(content, &theme); err != nil {
-		utils.Error("Invalid 'theme.json': %v", err)
-		hasErrors = true
+	theme, err := parseThemeManifest(content)
+	if err != nil {
+		report.errorf(ruleManifest, "theme.json", err.Error())
 	} else {
 		if theme.Name == "" {
-			utils.Error("theme.json missing 'name'")
-			hasErrors = true
+			report.errorf(ruleManifest, "theme.json", "theme.json missing 'name'")
 		}
 		if theme.Version == "" {
-			utils.Error("theme.json missing 'version'")
-			hasErrors = true
+			report.errorf(ruleManifest, "theme.json", "theme.json missing 'version'")
 		}
 
-		// 2. Check CSS files based on modes
-		modes := theme.Modes
-		if len(modes) == 0 {
-			modes = []string{"dark"} // default if not specified? Assuming similar logic to registry
+		for _, problem := range theme.modeProblems() {
+			report.errorf(ruleManifest, "theme.json", "theme.json: "+problem)
 		}
 
-		for _, mode := range modes {
-			cssFile := filepath.Join(dir, mode+".css")
+		// 2. Check CSS files based on modes
+		checked := map[string]bool{}
+		for _, mode := range theme.CSSModes() {
+			if !ModeName.MatchString(mode) || checked[mode] {
+				continue
+			}
+			checked[mode] = true
+
+			cssFile := themeStylesheet(dir, mode)
 			if _, err := os.Stat(cssFile); os.IsNotExist(err) {
-				utils.Error("Missing required CSS file for mode '%s': %s", mode, mode+".css")
-				hasErrors = true
+				report.errorf(ruleCSSFile, mode+".css", fmt.Sprintf("Missing required CSS file for mode '%s': %s", mode, mode+".css"))
+				continue
+			}
+			sheet := validateThemeCSS(report, rules, cssFile)
+			if opts.A11y && sheet != nil {
+				checkContrast(report, rules, report.rel(cssFile), sheet)
 			}
 		}
 	}
 
-	if hasErrors {
-		return fmt.Errorf("theme validation failed")
+	report.summary()
+
+	if report.HasErrors() {
+		return report, fmt.Errorf("theme validation failed")
 	}
 
+	report.Valid = true
 	utils.Success("Theme validation passed!")
-	return nil
+	return report, nil
 }
@@ -0,0 +1,156 @@
+package validate
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"inkdown-cli/internal/css"
+)
+
+// internalSelectors match selectors that reach into the app's DOM instead of
+// the documented surface: the .theme-<mode> class, plain elements and the
+// Inkdown variables. Attributes marks the patterns that look inside attribute selectors.
+var internalSelectors = []struct {
+	Pattern    *regexp.Regexp
+	Target     string
+	Attributes bool
+}{
+	{regexp.MustCompile(`(^|[^\w-])#[A-Za-z_-]`), "an element id", false},
+	{regexp.MustCompile(`\.cm-`), "CodeMirror internals (.cm-*)", false},
+	{regexp.MustCompile(`\[\s*data-tauri`), "Tauri internals ([data-tauri*])", true},
+}
+
+// internalSelectorTarget returns what selector reaches into when it matches
+// one of the internalSelectors.
+func internalSelectorTarget(selector string) (string, bool) {
+	for _, internal := range internalSelectors {
+		if internal.Pattern.MatchString(selectorText(selector, internal.Attributes)) {
+			return internal.Target, true
+		}
+	}
+	return "", false
+}
+
+// selectorText empties the quoted strings of selector, and the attribute
+// selectors too unless attrs is set, so that values such as the "#top" of
+// a[href="#top"] are not matched as selectors.
+func selectorText(selector string, attrs bool) string {
+	var sb strings.Builder
+	var quote rune
+	escaped, depth := false, 0
+	hidden := func() bool { return quote != 0 || (depth > 0 && !attrs) }
+	for _, r := range selector {
+		before := hidden()
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '[':
+			depth++
+		case r == ']' && depth > 0:
+			depth--
+		}
+		// Keep the delimiters of what is emptied.
+		if !before || !hidden() {
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// validateThemeCSS parses one mode stylesheet and reports syntax errors,
+// remote loads, internal selectors and missing variables. It returns the
+// parsed stylesheet, or nil when it could not be read.
+func validateThemeCSS(report *Report, rules Rules, path string) *css.Stylesheet {
+	file := report.rel(path)
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		report.errorf(ruleCSSFile, file, fmt.Sprintf("Could not read %s: %v", file, err))
+		return nil
+	}
+
+	sheet, err := css.Parse(string(content))
+	if err != nil {
+		f := Finding{
+			Severity: SeverityError,
+			Message:  fmt.Sprintf("Could not parse %s: %v", file, err),
+			File:     file,
+			Rule:     ruleParse,
+		}
+		if syntaxErr, ok := err.(*css.SyntaxError); ok {
+			f.Line, f.Column = syntaxErr.Line, syntaxErr.Col
+		}
+		report.add(f)
+		return nil
+	}
+
+	add := func(rule string, pos css.Pos, message string) {
+		if f, ok := rules.finding(rule, message); ok {
+			f.File, f.Line, f.Column = file, pos.Line, pos.Col
+			report.add(f)
+		}
+	}
+
+	for _, u := range sheet.URLs {
+		if !isRemoteURL(u.Value) {
+			continue
+		}
+		if u.Import {
+			add("no-remote-import", u.Pos, fmt.Sprintf("Remote @import of %s in %s:%d:%d", u.Value, file, u.Line, u.Col))
+		} else {
+			add("no-remote-url", u.Pos, fmt.Sprintf("Remote url(%s) in %s:%d:%d", u.Value, file, u.Line, u.Col))
+		}
+	}
+
+	defined := map[string]bool{}
+	sheet.Walk(func(r *css.Rule, inKeyframes bool) {
+		for _, d := range r.Declarations {
+			if strings.HasPrefix(d.Property, "--") {
+				defined[d.Property] = true
+			}
+		}
+
+		if r.AtRule != "" || inKeyframes {
+			return
+		}
+		if target, ok := internalSelectorTarget(r.Selector); ok {
+			add("no-internal-selectors", r.Pos, fmt.Sprintf("Selector %q in %s:%d:%d targets %s", r.Selector, file, r.Line, r.Col, target))
+		}
+	})
+
+	var missing []string
+	for _, name := range rules.option("required-variables", "variables") {
+		if !defined[name] {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		if f, ok := rules.finding("required-variables", fmt.Sprintf("%s does not define %s", file, strings.Join(missing, ", "))); ok {
+			f.File = file
+			report.add(f)
+		}
+	}
+
+	return sheet
+}
+
+func isRemoteURL(value string) bool {
+	v := strings.ToLower(value)
+	return strings.HasPrefix(v, "http://") || strings.HasPrefix(v, "https://") || strings.HasPrefix(v, "//")
+}
+
+// themeStylesheet returns the path of the stylesheet for mode in dir.
+func themeStylesheet(dir, mode string) string {
+	return filepath.Join(dir, mode+".css")
+}
@@ -0,0 +1,33 @@
+package validate
+
+import "testing"
+
+func TestInternalSelectorTarget(t *testing.T) {
+	tests := []struct {
+		selector string
+		target   string
+	}{
+		{".theme-dark a", ""},
+		{".theme-dark #sidebar", "an element id"},
+		{"#app > div", "an element id"},
+		{"a:not(#x)", "an element id"},
+		{`a[href="#top"]`, ""},
+		{`a[href='#top'] span`, ""},
+		{`a[href="#top"] #nav`, "an element id"},
+		{`[title="a]#b"]`, ""},
+		{`.a[data-x="\"#y"]`, ""},
+		{`a::after`, ""},
+		{"h1.title-1", ""},
+		{".theme-light .cm-line", "CodeMirror internals (.cm-*)"},
+		{`a[title=".cm-x"]`, ""},
+		{"[data-tauri-drag-region]", "Tauri internals ([data-tauri*])"},
+		{`a[title="[data-tauri"]`, ""},
+	}
+
+	for _, tt := range tests {
+		target, ok := internalSelectorTarget(tt.selector)
+		if target != tt.target || ok != (tt.target != "") {
+			t.Errorf("internalSelectorTarget(%q) = %q, %v, want %q", tt.selector, target, ok, tt.target)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package validate
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func writeTheme(t *testing.T, manifest string, modes ...string) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "theme.json"), []byte(manifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, mode := range modes {
+		if err := os.WriteFile(filepath.Join(dir, mode+".css"), []byte(".theme-"+mode+" {}"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestThemeModes(t *testing.T) {
+	dir := writeTheme(t, `{"name": "T", "version": "1.0.0", "modes": ["dark", "../x", "Light", "dark"]}`, "dark")
+
+	if _, err := LoadThemeManifest(dir); err == nil {
+		t.Error("LoadThemeManifest accepted invalid and duplicate modes")
+	}
+
+	report, err := ValidateTheme(dir, ThemeOptions{})
+	if err == nil {
+		t.Fatal("ValidateTheme passed a theme with invalid modes")
+	}
+	var manifest []string
+	for _, f := range report.Findings {
+		if f.Rule == ruleManifest {
+			manifest = append(manifest, f.Message)
+		}
+		if f.Rule == ruleCSSFile {
+			t.Errorf("stylesheet of an invalid mode was looked up: %s", f.Message)
+		}
+	}
+	want := []string{
+		`theme.json: invalid mode "../x": use lowercase letters, digits and dashes`,
+		`theme.json: invalid mode "Light": use lowercase letters, digits and dashes`,
+		`theme.json: duplicate mode "dark"`,
+	}
+	if !slices.Equal(manifest, want) {
+		t.Errorf("manifest findings = %q, want %q", manifest, want)
+	}
+}
+
+func TestLoadThemeManifest(t *testing.T) {
+	dir := writeTheme(t, `{"name": "T", "version": "1.0.0", "modes": ["dark", "high-contrast"]}`)
+
+	theme, err := LoadThemeManifest(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"dark", "high-contrast"}; !slices.Equal(theme.CSSModes(), want) {
+		t.Errorf("CSSModes() = %q, want %q", theme.CSSModes(), want)
+	}
+}
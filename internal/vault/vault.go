@@ -0,0 +1,122 @@
+// Package vault installs plugins and themes under development into a local
+// Inkdown vault.
+package vault
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"inkdown-cli/utils"
+)
+
+// ConfigDir holds the app data of a vault, relative to its root.
+const ConfigDir = ".inkdown"
+
+// ReloadMarker is rewritten after every sync. The app watches it to
+// hot-reload the plugin or theme in the same directory.
+const ReloadMarker = ".reload"
+
+// Open checks that path is a directory and returns it as an absolute path.
+func Open(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+
+	info, err := os.Stat(abs)
+	if err != nil {
+		return "", fmt.Errorf("vault not found: %s", abs)
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("vault must be a directory: %s", abs)
+	}
+
+	if _, err := os.Stat(filepath.Join(abs, ConfigDir)); os.IsNotExist(err) {
+		utils.Warn("%s has no %s directory yet; it will be created.", abs, ConfigDir)
+	}
+	return abs, nil
+}
+
+// PluginDir is where the app loads the plugin with id from.
+func PluginDir(vault, id string) string {
+	return filepath.Join(vault, ConfigDir, "plugins", id)
+}
+
+// ThemeDir is where the app loads the theme with id from.
+func ThemeDir(vault, id string) string {
+	return filepath.Join(vault, ConfigDir, "themes", id)
+}
+
+// Sync installs files from srcDir into destDir, as copies or as symlinks.
+// Files missing from srcDir are removed from destDir. It returns the names
+// of the installed files.
+func Sync(srcDir, destDir string, files []string, symlink bool) ([]string, error) {
+	if err := os.MkdirAll(destDir, 0755); err != nil {
+		return nil, err
+	}
+
+	var synced []string
+	for _, name := range files {
+		src := filepath.Join(srcDir, name)
+		dest := filepath.Join(destDir, name)
+
+		if _, err := os.Stat(src); os.IsNotExist(err) {
+			if err := os.Remove(dest); err != nil && !os.IsNotExist(err) {
+				return synced, err
+			}
+			continue
+		}
+
+		var err error
+		if symlink {
+			err = link(src, dest)
+		} else {
+			err = copyFile(src, dest)
+		}
+		if err != nil {
+			return synced, fmt.Errorf("could not install %s: %v", name, err)
+		}
+		synced = append(synced, name)
+	}
+	return synced, nil
+}
+
+// link points dest at src unless it already does.
+func link(src, dest string) error {
+	if target, err := os.Readlink(dest); err == nil && target == src {
+		return nil
+	}
+	if err := os.Remove(dest); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return os.Symlink(src, dest)
+}
+
+// copyFile replaces dest atomically so the app never reads a partial file.
+func copyFile(src, dest string) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+
+	// A previous symlink would otherwise be written through.
+	if info, err := os.Lstat(dest); err == nil && info.Mode()&os.ModeSymlink != 0 {
+		if err := os.Remove(dest); err != nil {
+			return err
+		}
+	}
+
+	tmp := dest + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, dest)
+}
+
+// MarkReload rewrites the reload marker in dir with the current time.
+func MarkReload(dir string) error {
+	stamp := time.Now().UTC().Format(time.RFC3339Nano) + "\n"
+	return os.WriteFile(filepath.Join(dir, ReloadMarker), []byte(stamp), 0644)
+}
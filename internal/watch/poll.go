@@ -0,0 +1,69 @@
+// Package watch detects file changes by polling modification times, which
+// works the same on every platform and file system.
+package watch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// DefaultInterval is how often files are checked.
+const DefaultInterval = 300 * time.Millisecond
+
+type stamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// Poll calls onChange with the paths, relative to dir, that were created,
+// modified or removed since the previous check, until ctx is done. files
+// lists the paths to watch and is called on every check, so it may glob.
+func Poll(ctx context.Context, dir string, interval time.Duration, files func() []string, onChange func(changed []string)) {
+	previous := snapshot(dir, files())
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		current := snapshot(dir, files())
+
+		var changed []string
+		for name, s := range current {
+			if old, ok := previous[name]; !ok || old != s {
+				changed = append(changed, name)
+			}
+		}
+		for name := range previous {
+			if _, ok := current[name]; !ok {
+				changed = append(changed, name)
+			}
+		}
+		previous = current
+
+		if len(changed) > 0 {
+			sort.Strings(changed)
+			onChange(changed)
+		}
+	}
+}
+
+func snapshot(dir string, files []string) map[string]stamp {
+	stamps := make(map[string]stamp, len(files))
+	for _, name := range files {
+		info, err := os.Stat(filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+		stamps[name] = stamp{modTime: info.ModTime(), size: info.Size()}
+	}
+	return stamps
+}
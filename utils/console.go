@@ -2,6 +2,8 @@ package utils
 
 import (
 	"fmt"
+	"io"
+	"os"
 )
 
 const (
@@ -14,30 +16,56 @@ const (
 	colorBold   = "\033[1m"
 )
 
+// logOut receives all human-oriented output. It is stdout by default and
+// stderr in JSON mode, so stdout only carries the JSON document.
+var logOut io.Writer = os.Stdout
+
+// LogWriter returns the writer human-oriented output goes to, e.g. for the
+// output of child processes.
+func LogWriter() io.Writer {
+	return logOut
+}
+
+// SetLogWriter redirects human-oriented output, e.g. to stderr when stdout
+// carries a machine-readable report.
+func SetLogWriter(w io.Writer) {
+	logOut = w
+}
+
 func colorize(color, s string) string {
 	return color + s + colorReset
 }
 
 func Info(s string, args ...interface{}) {
-	fmt.Println(colorize(colorCyan, fmt.Sprintf(s, args...)))
+	fmt.Fprintln(logOut, colorize(colorCyan, fmt.Sprintf(s, args...)))
 }
 
 func Success(s string, args ...interface{}) {
-	fmt.Println(colorize(colorGreen, fmt.Sprintf(s, args...)))
+	fmt.Fprintln(logOut, colorize(colorGreen, fmt.Sprintf(s, args...)))
 }
 
 func Error(s string, args ...interface{}) {
-	fmt.Println(colorize(colorRed, fmt.Sprintf(s, args...)))
+	fmt.Fprintln(logOut, colorize(colorRed, fmt.Sprintf(s, args...)))
 }
 
 func Prompt(s string, args ...interface{}) {
-	fmt.Print(colorize(colorYellow, fmt.Sprintf(s, args...)))
+	fmt.Fprint(logOut, colorize(colorYellow, fmt.Sprintf(s, args...)))
 }
 
 func Note(s string, args ...interface{}) {
-	fmt.Println(colorize(colorBlue, fmt.Sprintf(s, args...)))
+	fmt.Fprintln(logOut, colorize(colorBlue, fmt.Sprintf(s, args...)))
 }
 
 func Warn(s string, args ...interface{}) {
-	fmt.Println(colorize(colorYellow, fmt.Sprintf("[WARN] "+s, args...)))
+	fmt.Fprintln(logOut, colorize(colorYellow, fmt.Sprintf("[WARN] "+s, args...)))
+}
+
+// Printf writes uncolored human output.
+func Printf(s string, args ...interface{}) {
+	fmt.Fprintf(logOut, s, args...)
+}
+
+// Println writes uncolored human output followed by a newline.
+func Println(args ...interface{}) {
+	fmt.Fprintln(logOut, args...)
 }
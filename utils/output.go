@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"encoding/json"
+	"os"
+)
+
+var (
+	jsonOutput  bool
+	jsonEmitted bool
+)
+
+// SetJSONOutput switches the CLI to machine-readable output: commands print
+// a single JSON document to stdout and human logging moves to stderr.
+func SetJSONOutput(enabled bool) {
+	jsonOutput = enabled
+	if enabled {
+		logOut = os.Stderr
+	} else {
+		logOut = os.Stdout
+	}
+}
+
+func JSONOutput() bool {
+	return jsonOutput
+}
+
+// JSONEmitted reports whether a command already printed its JSON document.
+func JSONEmitted() bool {
+	return jsonEmitted
+}
+
+// PrintJSON writes v to stdout as indented JSON.
+func PrintJSON(v interface{}) error {
+	jsonEmitted = true
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(v)
+}
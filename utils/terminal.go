@@ -0,0 +1,27 @@
+package utils
+
+import "os"
+
+// IsInteractive reports whether stdin is a terminal the user can answer
+// prompts on. It is false in CI runners and when input is piped.
+func IsInteractive() bool {
+	info, err := os.Stdin.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// LogIsTerminal reports whether human output goes to a terminal, where it
+// can be redrawn in place, e.g. for a countdown.
+func LogIsTerminal() bool {
+	f, ok := logOut.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}